@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+
+	"google.golang.org/grpc/codes"
 )
 
 // promote standard library errors package functions.
@@ -38,26 +40,25 @@ func (t ErrorType) String() string {
 	return string(t)
 }
 
-// HTTPStatus returns the corresponding HTTP Status.
+// HTTPStatus returns the corresponding HTTP Status, -1 for an unknown type.
 func (t ErrorType) HTTPStatus() int {
-	switch t {
-	case ErrorTypeInvalid:
-		return http.StatusBadRequest
-	case ErrorTypeNotFound:
-		return http.StatusNotFound
-	case ErrorTypeUnprocessableContent:
-		return http.StatusUnprocessableEntity
-	case ErrorTypeUnauthorized:
-		return http.StatusUnauthorized
-	case ErrorTypeUnauthenticated:
-		return http.StatusUnauthorized
-	case ErrorTypeConflict:
-		return http.StatusConflict
-	case ErrorTypeInternalError, ErrorTypePanic:
-		return http.StatusInternalServerError
-	default:
+	m, ok := mappingOf(t)
+	if !ok {
 		return -1
 	}
+
+	return m.httpStatus
+}
+
+// GRPCCode returns the corresponding gRPC code, codes.Unknown for an unknown
+// type.
+func (t ErrorType) GRPCCode() codes.Code {
+	m, ok := mappingOf(t)
+	if !ok {
+		return codes.Unknown
+	}
+
+	return m.grpcCode
 }
 
 // HTTPStatusInt32Ptr returns the corresponding HTTP Status as an int32 pointer.
@@ -87,8 +88,86 @@ const (
 	ErrorTypeInternalError ErrorType = "internal-error"
 	// ErrorTypePanic represents errors from recovered panics.
 	ErrorTypePanic ErrorType = "panic"
+	// ErrorTypeForbidden represents access denied to an authenticated caller.
+	ErrorTypeForbidden ErrorType = "forbidden"
+	// ErrorTypeRateLimited represents callers exceeding their quota of requests.
+	ErrorTypeRateLimited ErrorType = "rate-limited"
+	// ErrorTypeUnavailable represents services temporarily unable to respond.
+	ErrorTypeUnavailable ErrorType = "unavailable"
+	// ErrorTypeTimeout represents operations that did not complete in time.
+	ErrorTypeTimeout ErrorType = "timeout"
+	// ErrorTypePreconditionFailed represents operations rejected because the
+	// system is not in the required state.
+	ErrorTypePreconditionFailed ErrorType = "precondition-failed"
+	// ErrorTypeCanceled represents operations canceled by the caller.
+	ErrorTypeCanceled ErrorType = "canceled"
 )
 
+// statusClientClosedRequest is the non-standard HTTP status of the requests
+// canceled by the client, introduced by nginx.
+const statusClientClosedRequest = 499
+
+// errorTypeMapping holds the HTTP status and gRPC code of an ErrorType.
+type errorTypeMapping struct {
+	errorType  ErrorType
+	httpStatus int
+	grpcCode   codes.Code
+}
+
+// errorTypeMappings is the mapping shared by the HTTP and gRPC servers and
+// clients. A status or code shared by several types maps back to the first
+// of them.
+var errorTypeMappings = []errorTypeMapping{
+	{ErrorTypeInvalid, http.StatusBadRequest, codes.InvalidArgument},
+	{ErrorTypeNotFound, http.StatusNotFound, codes.NotFound},
+	{ErrorTypeConflict, http.StatusConflict, codes.AlreadyExists},
+	{ErrorTypeUnprocessableContent, http.StatusUnprocessableEntity, codes.InvalidArgument},
+	{ErrorTypeUnauthenticated, http.StatusUnauthorized, codes.Unauthenticated},
+	{ErrorTypeForbidden, http.StatusForbidden, codes.PermissionDenied},
+	{ErrorTypeUnauthorized, http.StatusUnauthorized, codes.PermissionDenied},
+	{ErrorTypeRateLimited, http.StatusTooManyRequests, codes.ResourceExhausted},
+	{ErrorTypePreconditionFailed, http.StatusPreconditionFailed, codes.FailedPrecondition},
+	{ErrorTypeCanceled, statusClientClosedRequest, codes.Canceled},
+	{ErrorTypeTimeout, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+	{ErrorTypeUnavailable, http.StatusServiceUnavailable, codes.Unavailable},
+	{ErrorTypeInternalError, http.StatusInternalServerError, codes.Internal},
+	{ErrorTypePanic, http.StatusInternalServerError, codes.Internal},
+}
+
+func mappingOf(t ErrorType) (errorTypeMapping, bool) {
+	for _, m := range errorTypeMappings {
+		if m.errorType == t {
+			return m, true
+		}
+	}
+
+	return errorTypeMapping{}, false
+}
+
+// ErrorTypeFromHTTPStatus returns the ErrorType of an HTTP status, false
+// when no type maps to it.
+func ErrorTypeFromHTTPStatus(status int) (ErrorType, bool) {
+	for _, m := range errorTypeMappings {
+		if m.httpStatus == status {
+			return m.errorType, true
+		}
+	}
+
+	return "", false
+}
+
+// ErrorTypeFromGRPCCode returns the ErrorType of a gRPC code, false when no
+// type maps to it.
+func ErrorTypeFromGRPCCode(code codes.Code) (ErrorType, bool) {
+	for _, m := range errorTypeMappings {
+		if m.grpcCode == code {
+			return m.errorType, true
+		}
+	}
+
+	return "", false
+}
+
 // Error represents a structured error with additional context and details.
 // It implements the error interface while providing rich error information
 // that can be used for both logging and client responses.
@@ -103,8 +182,16 @@ type Error struct {
 	InternalMessage string
 	// ErrorDetails provides additional structured information about the error
 	ErrorDetails ErrorDetails
+	// Cause is the underlying error, returned by Unwrap so that Is and As see
+	// through the Error (not intended for client response)
+	Cause error
+
+	// stack holds the program counters captured by WithStack
+	stack []uintptr
 }
 
+// Error returns the description of the error. The cause and the stack are
+// left out, they are printed with the %+v verb.
 func (e *Error) Error() string {
 	return fmt.Sprintf(
 		"type: %s, code: %s, message: %s, internal message: %s",
@@ -161,6 +248,9 @@ type ErrorDetail struct {
 	Code ErrorDetailCode
 	// Message provides a description of the specific detail
 	Message string
+	// FieldPath is the path of the request field the detail is about,
+	// like "user.email", empty when it is not about a field
+	FieldPath string
 }
 
 // ErrorDetails is a collection of ErrorDetail that allows for reporting multiple
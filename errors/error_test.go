@@ -0,0 +1,96 @@
+package errors_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/purposeinplay/go-commons/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError_Unwrap(t *testing.T) {
+	req := require.New(t)
+
+	err := fmt.Errorf("get user: %w", &errors.Error{
+		Type:  errors.ErrorTypeNotFound,
+		Cause: sql.ErrNoRows,
+	})
+
+	req.ErrorIs(err, sql.ErrNoRows)
+	req.True(errors.IsErrorType(err, errors.ErrorTypeNotFound))
+}
+
+func TestWrap(t *testing.T) {
+	req := require.New(t)
+
+	err := errors.Wrap(sql.ErrNoRows, errors.ErrorTypeNotFound, "user not found")
+
+	req.ErrorIs(err, sql.ErrNoRows)
+	req.Equal("user not found", err.Message)
+
+	// The stack starts at the caller of Wrap.
+	req.NotEmpty(err.StackTrace())
+
+	formatted := fmt.Sprintf("%+v", err)
+
+	req.True(strings.HasPrefix(formatted, err.Error()+"\n"))
+	req.Contains(formatted, "errors_test.TestWrap")
+	req.Contains(formatted, "error_test.go:")
+	req.Contains(formatted, "cause: "+sql.ErrNoRows.Error())
+
+	// The other verbs print the error only.
+	req.Equal(err.Error(), fmt.Sprintf("%v", err))
+	req.Equal(err.Error(), fmt.Sprintf("%s", err))
+}
+
+func TestError_WithStack(t *testing.T) {
+	req := require.New(t)
+
+	err := &errors.Error{Type: errors.ErrorTypeInternalError}
+
+	req.Nil(err.StackTrace())
+	req.Equal(err.Error(), fmt.Sprintf("%+v", err))
+
+	req.Same(err, err.WithStack())
+	req.NotEmpty(err.StackTrace())
+}
+
+func TestError_LogValue(t *testing.T) {
+	req := require.New(t)
+
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	logger.Error("failed", slog.Any("error", errors.Wrap(
+		&errors.Error{Type: errors.ErrorTypeInvalid, Message: "invalid email"},
+		errors.ErrorTypeConflict,
+		"user exists",
+	)))
+
+	var record struct {
+		Error struct {
+			Type    string   `json:"type"`
+			Message string   `json:"message"`
+			Stack   []string `json:"stack"`
+			Cause   struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"cause"`
+		} `json:"error"`
+	}
+
+	req.NoError(json.Unmarshal(buf.Bytes(), &record))
+
+	req.Equal("conflict", record.Error.Type)
+	req.Equal("user exists", record.Error.Message)
+	req.NotEmpty(record.Error.Stack)
+	req.Contains(record.Error.Stack[0], "errors_test.TestError_LogValue")
+	req.Equal("invalid", record.Error.Cause.Type)
+	req.Equal("invalid email", record.Error.Cause.Message)
+}
@@ -101,3 +101,91 @@ func TestErrors(t *testing.T) {
 
 	<-done
 }
+
+// detailsServer returns appErr through the PanicErrorHandler.
+type detailsServer struct {
+	pb.UnimplementedGreeterServer
+	t      *testing.T
+	appErr *errors.Error
+}
+
+// SayHello implements helloworld.GreeterServer.
+func (s *detailsServer) SayHello(context.Context, *pb.HelloRequest) (*pb.HelloReply, error) {
+	sts, err := (errorsgrpc.PanicErrorHandler{}).ErrorToGRPCStatus(s.appErr)
+	if err != nil {
+		s.t.Fatalf("failed to convert error: %s", err)
+	}
+
+	return nil, sts.Err()
+}
+
+func TestErrorDetails(t *testing.T) {
+	t.Parallel()
+
+	req := require.New(t)
+
+	sent := &errors.Error{
+		Type:    errors.ErrorTypeInvalid,
+		Code:    "invalid_user",
+		Message: "invalid user",
+		ErrorDetails: errors.ErrorDetails{
+			{
+				Code:      "invalid_email",
+				Message:   "email is not valid",
+				FieldPath: "user.email",
+			},
+			{
+				Code:    "too_many_attempts",
+				Message: "retry later",
+			},
+		},
+	}
+
+	const bufSize = 1024 * 1024
+
+	var (
+		lis       = bufconn.Listen(bufSize)
+		bufDialer = func(context.Context, string) (net.Conn, error) { return lis.Dial() }
+	)
+
+	grpcServer := grpc.NewServer()
+
+	pb.RegisterGreeterServer(grpcServer, &detailsServer{t: t, appErr: sent})
+
+	t.Cleanup(grpcServer.Stop)
+
+	done := make(chan struct{}, 1)
+
+	go func() {
+		defer close(done)
+
+		serveErr := grpcServer.Serve(lis)
+		assert.NoError(t, serveErr)
+	}()
+
+	clientConn, err := grpc.NewClient(
+		"passthrough://bufnet",
+		grpc.WithContextDialer(bufDialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(errorsgrpc.UnmarshalErrorUnaryClientInterceptor()),
+	)
+	req.NoError(err)
+
+	t.Cleanup(func() { req.NoError(clientConn.Close()) })
+
+	greeterClient := pb.NewGreeterClient(clientConn)
+
+	_, err = greeterClient.SayHello(context.Background(), &pb.HelloRequest{})
+
+	var appErr *errors.Error
+
+	req.ErrorAs(err, &appErr)
+	req.Equal(sent, appErr)
+
+	req.True(errors.IsErrorDetailCode(err, "invalid_email"))
+	req.False(errors.IsErrorDetailCode(err, "invalid_name"))
+
+	grpcServer.Stop()
+
+	<-done
+}
@@ -40,9 +40,28 @@ func UnmarshalErrorUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 		}
 
 		return &errors.Error{
-			Type:    errors.ErrorType(sts.Message()),
-			Code:    errors.ErrorCode(errResp.ErrorCode),
-			Message: errResp.Message,
+			Type:         errors.ErrorType(sts.Message()),
+			Code:         errors.ErrorCode(errResp.ErrorCode),
+			Message:      errResp.Message,
+			ErrorDetails: errorDetailsFromProto(errResp.Details),
 		}
 	}
 }
+
+func errorDetailsFromProto(protoDetails []*commonserr.ErrorDetail) errors.ErrorDetails {
+	if len(protoDetails) == 0 {
+		return nil
+	}
+
+	details := make(errors.ErrorDetails, len(protoDetails))
+
+	for i, d := range protoDetails {
+		details[i] = errors.ErrorDetail{
+			Code:      errors.ErrorDetailCode(d.GetCode()),
+			Message:   d.GetMessage(),
+			FieldPath: d.GetFieldPath(),
+		}
+	}
+
+	return details
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/purposeinplay/go-commons/errors"
 	commonserr "github.com/purposeinplay/go-commons/errors/proto/commons/error/v1"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -65,15 +64,6 @@ func (PanicErrorHandler) IsApplicationError(err error) bool {
 	return errors.As(err, &applicationError)
 }
 
-var errorTypeToStatusCode = map[errors.ErrorType]codes.Code{
-	errors.ErrorTypeInvalid:              codes.InvalidArgument,
-	errors.ErrorTypeNotFound:             codes.NotFound,
-	errors.ErrorTypeUnprocessableContent: codes.Internal,
-	errors.ErrorTypeUnauthorized:         codes.PermissionDenied,
-	errors.ErrorTypeUnauthenticated:      codes.Unauthenticated,
-	errors.ErrorTypeInternalError:        codes.Internal,
-}
-
 // ErrNotApplicationError is returned whenever an error that is not an *errors.Error is given
 // to the PanicErrorHandler.ErrorToGRPCStatus method.
 var ErrNotApplicationError = errors.New("given error is not an application error")
@@ -92,12 +82,7 @@ func (PanicErrorHandler) ErrorToGRPCStatus(
 		return nil, ErrNotApplicationError
 	}
 
-	code, ok := errorTypeToStatusCode[applicationError.Type]
-	if !ok {
-		code = codes.Unknown
-	}
-
-	grpcStatus := status.New(code, applicationError.Type.String())
+	grpcStatus := status.New(applicationError.Type.GRPCCode(), applicationError.Type.String())
 
 	grpcStatusWithDetails, attachDetailsErr := grpcStatus.
 		WithDetails(proto.MessageV1(errorToErrorResponse(applicationError)))
@@ -138,5 +123,24 @@ func errorToErrorResponse(err *errors.Error) *commonserr.ErrorResponse {
 		//nolint:gosec // disable G115
 		ErrorCode: err.Code.String(),
 		Message:   err.Message,
+		Details:   errorDetailsToProto(err.ErrorDetails),
 	}
 }
+
+func errorDetailsToProto(details errors.ErrorDetails) []*commonserr.ErrorDetail {
+	if len(details) == 0 {
+		return nil
+	}
+
+	protoDetails := make([]*commonserr.ErrorDetail, len(details))
+
+	for i, d := range details {
+		protoDetails[i] = &commonserr.ErrorDetail{
+			Code:      d.Code.String(),
+			Message:   d.Message,
+			FieldPath: d.FieldPath,
+		}
+	}
+
+	return protoDetails
+}
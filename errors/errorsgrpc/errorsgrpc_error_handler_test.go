@@ -1,6 +1,7 @@
 package errorsgrpc_test
 
 import (
+	"net/http"
 	"strconv"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/purposeinplay/go-commons/errors/errorsgrpc"
 	commonserr "github.com/purposeinplay/go-commons/errors/proto/commons/error/v1"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 )
 
 func TestRetrieveDetails(t *testing.T) {
@@ -39,3 +41,100 @@ func TestRetrieveDetails(t *testing.T) {
 		),
 	)
 }
+
+func TestRetrieveDetails_ErrorDetails(t *testing.T) {
+	req := require.New(t)
+
+	appErr := &errors.Error{
+		Type:    errors.ErrorTypeInvalid,
+		Code:    "invalid_user",
+		Message: "invalid user",
+		ErrorDetails: errors.ErrorDetails{
+			{
+				Code:      "invalid_email",
+				Message:   "email is not valid",
+				FieldPath: "user.email",
+			},
+		},
+	}
+
+	sts, err := (&errorsgrpc.PanicErrorHandler{}).ErrorToGRPCStatus(appErr)
+	req.NoError(err)
+
+	req.Len(sts.Details(), 1)
+
+	details, ok := sts.Details()[0].(*commonserr.ErrorResponse)
+	req.True(ok)
+
+	req.True(
+		proto.Equal(
+			&commonserr.ErrorResponse{
+				ErrorCode: "invalid_user",
+				Message:   "invalid user",
+				Details: []*commonserr.ErrorDetail{
+					{
+						Code:      "invalid_email",
+						Message:   "email is not valid",
+						FieldPath: "user.email",
+					},
+				},
+			},
+			details,
+		),
+	)
+}
+
+func TestErrorToGRPCStatus_Codes(t *testing.T) {
+	tests := map[errors.ErrorType]codes.Code{
+		errors.ErrorTypeInvalid:              codes.InvalidArgument,
+		errors.ErrorTypeNotFound:             codes.NotFound,
+		errors.ErrorTypeConflict:             codes.AlreadyExists,
+		errors.ErrorTypeUnprocessableContent: codes.InvalidArgument,
+		errors.ErrorTypeUnauthorized:         codes.PermissionDenied,
+		errors.ErrorTypeUnauthenticated:      codes.Unauthenticated,
+		errors.ErrorTypeForbidden:            codes.PermissionDenied,
+		errors.ErrorTypeRateLimited:          codes.ResourceExhausted,
+		errors.ErrorTypeUnavailable:          codes.Unavailable,
+		errors.ErrorTypeTimeout:              codes.DeadlineExceeded,
+		errors.ErrorTypePreconditionFailed:   codes.FailedPrecondition,
+		errors.ErrorTypeCanceled:             codes.Canceled,
+		errors.ErrorTypeInternalError:        codes.Internal,
+		errors.ErrorTypePanic:                codes.Internal,
+		errors.ErrorType("unknown"):          codes.Unknown,
+	}
+
+	for typ, code := range tests {
+		t.Run(typ.String(), func(t *testing.T) {
+			req := require.New(t)
+
+			sts, err := (&errorsgrpc.PanicErrorHandler{}).ErrorToGRPCStatus(&errors.Error{Type: typ})
+			req.NoError(err)
+
+			req.Equal(code, sts.Code())
+			req.Equal(typ.String(), sts.Message())
+		})
+	}
+}
+
+func TestErrorTypeFromGRPCCode(t *testing.T) {
+	req := require.New(t)
+
+	// A code shared by several types maps back to the first one.
+	typ, ok := errors.ErrorTypeFromGRPCCode(codes.PermissionDenied)
+	req.True(ok)
+	req.Equal(errors.ErrorTypeForbidden, typ)
+
+	typ, ok = errors.ErrorTypeFromGRPCCode(codes.ResourceExhausted)
+	req.True(ok)
+	req.Equal(errors.ErrorTypeRateLimited, typ)
+
+	_, ok = errors.ErrorTypeFromGRPCCode(codes.DataLoss)
+	req.False(ok)
+
+	typ, ok = errors.ErrorTypeFromHTTPStatus(http.StatusUnauthorized)
+	req.True(ok)
+	req.Equal(errors.ErrorTypeUnauthenticated, typ)
+
+	req.Equal(http.StatusTooManyRequests, errors.ErrorTypeRateLimited.HTTPStatus())
+	req.Equal(-1, errors.ErrorType("unknown").HTTPStatus())
+}
@@ -27,7 +27,10 @@ type ErrorResponse struct {
 	// The application error code
 	ErrorCode string `protobuf:"bytes,1,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
 	// A field containing extra details about the error.
-	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Additional context about the error, like the field-level validation
+	// errors.
+	Details       []*ErrorDetail `protobuf:"bytes,3,rep,name=details,proto3" json:"details,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -76,15 +79,92 @@ func (x *ErrorResponse) GetMessage() string {
 	return ""
 }
 
+func (x *ErrorResponse) GetDetails() []*ErrorDetail {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+// A specific issue within an error.
+type ErrorDetail struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The code identifying the detail.
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// A description of the detail.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// The path of the request field the detail is about, like "user.email".
+	FieldPath     string `protobuf:"bytes,3,opt,name=field_path,json=fieldPath,proto3" json:"field_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorDetail) Reset() {
+	*x = ErrorDetail{}
+	mi := &file_commons_error_v1_error_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorDetail) ProtoMessage() {}
+
+func (x *ErrorDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_commons_error_v1_error_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorDetail.ProtoReflect.Descriptor instead.
+func (*ErrorDetail) Descriptor() ([]byte, []int) {
+	return file_commons_error_v1_error_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ErrorDetail) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetFieldPath() string {
+	if x != nil {
+		return x.FieldPath
+	}
+	return ""
+}
+
 var File_commons_error_v1_error_proto protoreflect.FileDescriptor
 
 const file_commons_error_v1_error_proto_rawDesc = "" +
 	"\n" +
-	"\x1ccommons/error/v1/error.proto\x12\x10commons.error.v1\"H\n" +
+	"\x1ccommons/error/v1/error.proto\x12\x10commons.error.v1\"\x81\x01\n" +
 	"\rErrorResponse\x12\x1d\n" +
 	"\n" +
 	"error_code\x18\x01 \x01(\tR\terrorCode\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessageBNZLgithub.com/purposeinplay/go-commons/errors/proto/commons/error/v1;commonserrb\x06proto3"
+	"\amessage\x18\x02 \x01(\tR\amessage\x127\n" +
+	"\adetails\x18\x03 \x03(\v2\x1d.commons.error.v1.ErrorDetailR\adetails\"Z\n" +
+	"\vErrorDetail\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"field_path\x18\x03 \x01(\tR\tfieldPathBNZLgithub.com/purposeinplay/go-commons/errors/proto/commons/error/v1;commonserrb\x06proto3"
 
 var (
 	file_commons_error_v1_error_proto_rawDescOnce sync.Once
@@ -98,16 +178,18 @@ func file_commons_error_v1_error_proto_rawDescGZIP() []byte {
 	return file_commons_error_v1_error_proto_rawDescData
 }
 
-var file_commons_error_v1_error_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_commons_error_v1_error_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_commons_error_v1_error_proto_goTypes = []any{
 	(*ErrorResponse)(nil), // 0: commons.error.v1.ErrorResponse
+	(*ErrorDetail)(nil),   // 1: commons.error.v1.ErrorDetail
 }
 var file_commons_error_v1_error_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1, // 0: commons.error.v1.ErrorResponse.details:type_name -> commons.error.v1.ErrorDetail
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_commons_error_v1_error_proto_init() }
@@ -121,7 +203,7 @@ func file_commons_error_v1_error_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_commons_error_v1_error_proto_rawDesc), len(file_commons_error_v1_error_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
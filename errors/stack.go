@@ -0,0 +1,138 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+)
+
+// maxStackDepth is the maximum number of frames captured by WithStack.
+const maxStackDepth = 32
+
+// Wrap returns an Error of type typ with message, wrapping cause and
+// capturing the stack of the caller.
+func Wrap(cause error, typ ErrorType, message string) *Error {
+	e := &Error{
+		Type:    typ,
+		Message: message,
+		Cause:   cause,
+	}
+
+	e.stack = callers()
+
+	return e
+}
+
+// WithStack captures the stack of the caller in e and returns e:
+//
+//	return (&errors.Error{Type: errors.ErrorTypeNotFound, Cause: err}).WithStack()
+func (e *Error) WithStack() *Error {
+	e.stack = callers()
+
+	return e
+}
+
+// callers returns the program counters of the caller of its caller.
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+
+	// Skip runtime.Callers, callers and the constructor.
+	n := runtime.Callers(3, pcs)
+
+	return pcs[:n]
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// StackTrace returns the program counters of the stack captured by Wrap or
+// WithStack, nil when none was captured. The error reporters read it, it
+// has the signature Sentry looks for.
+func (e *Error) StackTrace() []uintptr {
+	return e.stack
+}
+
+// Format implements fmt.Formatter. The %+v verb prints the error followed
+// by its stack and its cause, also with %+v.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		_, _ = io.WriteString(s, e.Error())
+
+		if !s.Flag('+') {
+			return
+		}
+
+		for _, frame := range e.frames() {
+			_, _ = io.WriteString(s, "\n"+frame.Function+"\n\t"+frame.File+":"+strconv.Itoa(frame.Line))
+		}
+
+		if e.Cause != nil {
+			_, _ = fmt.Fprintf(s, "\ncause: %+v", e.Cause)
+		}
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// LogValue implements slog.LogValuer, logging the fields of the error as a
+// group, with its cause and stack when set.
+func (e *Error) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("type", e.Type.String()),
+		slog.String("code", e.Code.String()),
+		slog.String("message", e.Message),
+	}
+
+	if e.InternalMessage != "" {
+		attrs = append(attrs, slog.String("internal_message", e.InternalMessage))
+	}
+
+	if len(e.ErrorDetails) > 0 {
+		attrs = append(attrs, slog.Any("details", e.ErrorDetails))
+	}
+
+	if e.Cause != nil {
+		attrs = append(attrs, slog.Any("cause", e.Cause))
+	}
+
+	if frames := e.frames(); len(frames) > 0 {
+		stack := make([]string, len(frames))
+
+		for i, frame := range frames {
+			stack[i] = frame.Function + " " + frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+
+		attrs = append(attrs, slog.Any("stack", stack))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// frames returns the frames of the captured stack.
+func (e *Error) frames() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+
+	var (
+		frames []runtime.Frame
+		it     = runtime.CallersFrames(e.stack)
+	)
+
+	for {
+		frame, more := it.Next()
+
+		frames = append(frames, frame)
+
+		if !more {
+			return frames
+		}
+	}
+}
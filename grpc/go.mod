@@ -15,7 +15,7 @@ require (
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.26.3
 	github.com/matryer/is v1.4.1
 	github.com/oklog/run v1.1.0
-	github.com/purposeinplay/go-commons/errors v0.0.14
+	github.com/purposeinplay/go-commons/errors v0.0.16
 	github.com/rs/cors v1.11.1
 	go.opencensus.io v0.24.0
 	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.61.0
@@ -5,10 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/golang/protobuf/proto"
 	grpcrecovery "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"github.com/purposeinplay/go-commons/errors"
-	commonserr "github.com/purposeinplay/go-commons/errors/proto/commons/error/v1"
+	"github.com/purposeinplay/go-commons/errors/errorsgrpc"
 	"github.com/purposeinplay/go-commons/grpc/grpcutils"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
@@ -78,31 +77,14 @@ func errorHandlerUnaryServerInterceptor(
 				errorReporter.ReportError(ctx, appError)
 			}
 
-			code := codes.Unknown
-
-			var errorTypeToStatusCode = map[errors.ErrorType]codes.Code{
-				errors.ErrorTypeInvalid:              codes.InvalidArgument,
-				errors.ErrorTypeNotFound:             codes.NotFound,
-				errors.ErrorTypeUnprocessableContent: codes.Internal,
-				errors.ErrorTypeUnauthorized:         codes.PermissionDenied,
-				errors.ErrorTypeUnauthenticated:      codes.Unauthenticated,
-				errors.ErrorTypeInternalError:        codes.Internal,
-				errors.ErrorTypePanic:                codes.Internal,
-			}
-
-			c, ok := errorTypeToStatusCode[appError.Type]
-			if ok {
-				code = c
+			// The status carries the code mapped from the error type and the
+			// error details.
+			sts, stsErr := errorsgrpc.PanicErrorHandler{}.ErrorToGRPCStatus(appError)
+			if stsErr != nil {
+				sts = status.New(appError.Type.GRPCCode(), appError.Type.String())
 			}
 
-			grpcStatus = status.New(code, appError.Type.String())
-
-			grpcStatus, _ = grpcStatus.WithDetails(
-				proto.MessageV1(
-					&commonserr.ErrorResponse{
-						ErrorCode: appError.Code.String(),
-						Message:   appError.Message,
-					}))
+			grpcStatus = sts
 		} else {
 			grpcStatus = status.New(codes.Internal, err.Error())
 
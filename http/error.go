@@ -1,12 +1,12 @@
 package http
 
 import (
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/purposeinplay/go-commons/errors"
 	"github.com/purposeinplay/go-commons/http/render"
 )
 
@@ -81,6 +81,21 @@ func UnprocessableEntityError(fmtString string, args ...interface{}) *HTTPError
 	return httpError(http.StatusUnprocessableEntity, fmtString, args...)
 }
 
+// ApplicationError converts an application error to an HTTPError with the
+// HTTP status of its type, 500 for the types without one.
+func ApplicationError(err *errors.Error) *HTTPError {
+	code := err.Type.HTTPStatus()
+	if code < 0 {
+		code = http.StatusInternalServerError
+	}
+
+	return &HTTPError{
+		Code:          code,
+		Message:       err.Message,
+		InternalError: err,
+	}
+}
+
 // HTTPError is an error with a message and an HTTP status code.
 type HTTPError struct {
 	Code            int    `json:"code"`
@@ -136,7 +151,11 @@ func HandleError(httpErr error, w http.ResponseWriter, r *http.Request) {
 	logger := loggerFor(r)
 	errorID := middleware.GetReqID(r.Context())
 
-	var e *HTTPError
+	var (
+		e      *HTTPError
+		appErr *errors.Error
+	)
+
 	switch {
 	case errors.As(httpErr, &e):
 		if e.Code >= http.StatusInternalServerError {
@@ -149,6 +168,8 @@ func HandleError(httpErr error, w http.ResponseWriter, r *http.Request) {
 		if err := render.SendJSON(w, e.Code, e); err != nil {
 			HandleError(err, w, r)
 		}
+	case errors.As(httpErr, &appErr):
+		HandleError(ApplicationError(appErr), w, r)
 	default:
 		logger.With(slog.Any("error", httpErr)).Error(httpErr.Error())
 
@@ -5,4 +5,10 @@ go 1.24
 require (
 	github.com/go-chi/chi/v5 v5.0.7
 	github.com/go-chi/cors v1.2.1
+	github.com/purposeinplay/go-commons/errors v0.0.16
+)
+
+require (
+	golang.org/x/sys v0.31.0 // indirect
+	google.golang.org/grpc v1.71.0 // indirect
 )
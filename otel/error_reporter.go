@@ -2,11 +2,15 @@ package otel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.opentelemetry.io/otel/attribute"
 	"log/slog"
+	"runtime"
+	"strings"
 
 	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.23.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -29,7 +33,9 @@ type ErrorReporter struct {
 //   - Always log the error using the structured logger
 //   - Record the error in an OpenTelemetry span if tracing is available
 //   - Create a new span if no active span exists but a TraceProvider is configured
-//   - Set the span status to error and record stack trace information
+//   - Set the span status to error and record stack trace information, the
+//     stack captured by the error when it has a StackTrace() []uintptr method
+//     like the go-commons errors.Error, or the stack of the caller
 //
 // Parameters:
 //   - ctx: Context that may contain an active OpenTelemetry span
@@ -74,8 +80,13 @@ func (r ErrorReporter) recordErrorInSpan(ctx context.Context, err error) {
 		defer span.End()
 	}
 
-	// Record the error in the span with stack trace
-	span.RecordError(err, trace.WithStackTrace(true))
+	// Record the error in the span with the stack captured by the error when
+	// it has one, or the stack of the caller.
+	if stack := errorStack(err); stack != "" {
+		span.RecordError(err, trace.WithAttributes(semconv.ExceptionStacktrace(stack)))
+	} else {
+		span.RecordError(err, trace.WithStackTrace(true))
+	}
 	span.SetStatus(codes.Error, "internal error")
 
 	// Add additional error context as span attributes
@@ -85,6 +96,39 @@ func (r ErrorReporter) recordErrorInSpan(ctx context.Context, err error) {
 	)
 }
 
+// stackTracer is implemented by the errors capturing their stack, like
+// the go-commons errors.Error.
+type stackTracer interface {
+	StackTrace() []uintptr
+}
+
+// errorStack returns the stack captured by the first error of the chain of
+// err that has one, formatted like a panic, or an empty string.
+func errorStack(err error) string {
+	for err != nil {
+		if st, ok := err.(stackTracer); ok && len(st.StackTrace()) > 0 {
+			var (
+				b      strings.Builder
+				frames = runtime.CallersFrames(st.StackTrace())
+			)
+
+			for {
+				frame, more := frames.Next()
+
+				fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+
+				if !more {
+					return b.String()
+				}
+			}
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return ""
+}
+
 // scopeName defines the instrumentation scope for OpenTelemetry tracing.
 // This should match your module/package name for proper trace attribution.
 const scopeName = "github.com/purposeinplay/go-commons/otel"
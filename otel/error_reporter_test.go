@@ -0,0 +1,69 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.23.0"
+)
+
+// stackError captures its stack like the go-commons errors.Error.
+type stackError struct {
+	stack []uintptr
+}
+
+func newStackError() *stackError {
+	pcs := make([]uintptr, 32)
+
+	n := runtime.Callers(2, pcs)
+
+	return &stackError{stack: pcs[:n]}
+}
+
+func (*stackError) Error() string { return "stack error" }
+
+func (e *stackError) StackTrace() []uintptr { return e.stack }
+
+func TestErrorReporter_Stack(t *testing.T) {
+	req := require.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+
+	reporter := ErrorReporter{
+		Logger:        slog.New(slog.DiscardHandler),
+		TraceProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	}
+
+	// The stack captured by an error of the chain is recorded.
+	reporter.ReportError(context.Background(), fmt.Errorf("wrapped: %w", newStackError()))
+
+	// Otherwise the stack of the caller.
+	reporter.ReportError(context.Background(), errors.New("plain error"))
+
+	spans := recorder.Ended()
+	req.Len(spans, 2)
+
+	stacks := make([]string, len(spans))
+
+	for i, span := range spans {
+		req.Len(span.Events(), 1)
+
+		for _, attr := range span.Events()[0].Attributes {
+			if attr.Key == semconv.ExceptionStacktraceKey {
+				stacks[i] = attr.Value.AsString()
+			}
+		}
+	}
+
+	req.Contains(stacks[0], "otel.TestErrorReporter_Stack")
+	req.NotContains(stacks[0], "recordErrorInSpan")
+
+	req.Contains(stacks[1], "recordErrorInSpan")
+}
@@ -76,6 +76,7 @@ func (p GORMErrorsPlugin) handleError(tx *gorm.DB) {
 
 	txErr := &errors.Error{
 		InternalMessage: tx.Error.Error(),
+		Cause:           err,
 	}
 
 	switch {
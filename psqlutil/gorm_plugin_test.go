@@ -28,6 +28,7 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				Type:            errors.ErrorTypeNotFound,
 				Message:         "record not found",
 				InternalMessage: gorm.ErrRecordNotFound.Error(),
+				Cause:           gorm.ErrRecordNotFound,
 			},
 		},
 		"SQLNoRowsError": {
@@ -36,6 +37,7 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				Type:            errors.ErrorTypeNotFound,
 				Message:         "record not found",
 				InternalMessage: sql.ErrNoRows.Error(),
+				Cause:           sql.ErrNoRows,
 			},
 		},
 		"UniqueViolationError": {
@@ -46,6 +48,7 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				Type:            errors.ErrorTypeConflict,
 				Message:         "object already exists",
 				InternalMessage: (&pq.Error{Code: "23505"}).Error(),
+				Cause:           &pq.Error{Code: "23505"},
 			},
 		},
 		"InvalidInputError": {
@@ -56,6 +59,7 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				Type:            errors.ErrorTypeInvalid,
 				Message:         "invalid input",
 				InternalMessage: (&pq.Error{Code: "22P02"}).Error(),
+				Cause:           &pq.Error{Code: "22P02"},
 			},
 		},
 		"ForeignKeyViolationError": {
@@ -66,6 +70,7 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				Type:            errors.ErrorTypeInvalid,
 				Message:         "invalid input",
 				InternalMessage: (&pq.Error{Code: "23503"}).Error(),
+				Cause:           &pq.Error{Code: "23503"},
 			},
 		},
 		"CheckConstraintViolationError": {
@@ -76,6 +81,7 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				Type:            errors.ErrorTypeInvalid,
 				Message:         "invalid input",
 				InternalMessage: (&pq.Error{Code: "23514"}).Error(),
+				Cause:           &pq.Error{Code: "23514"},
 			},
 		},
 		"UnknownError": {
@@ -84,6 +90,7 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				Type:            errors.ErrorTypeInternalError,
 				Message:         "unknown error",
 				InternalMessage: "unknown error",
+				Cause:           errors.New("unknown error"),
 			},
 		},
 		"ErrorWithTableDetails": {
@@ -101,6 +108,7 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				Type:            errors.ErrorTypeNotFound,
 				Message:         "record not found",
 				InternalMessage: gorm.ErrRecordNotFound.Error(),
+				Cause:           gorm.ErrRecordNotFound,
 				ErrorDetails: errors.ErrorDetails{
 					{
 						Code:    "USER_NOT_FOUND",
@@ -137,6 +145,9 @@ func TestGORMErrorsPlugin_handleError(t *testing.T) {
 				actualErr, _ := db.Error.(*errors.Error)
 
 				assert.Equal(t, tt.expectedError, actualErr)
+
+				// The translated error wraps the original one.
+				assert.ErrorIs(t, db.Error, tt.inputErr)
 			}
 		})
 	}
@@ -0,0 +1,326 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const bridgeInstrumentationName = "github.com/purposeinplay/go-commons/pubsub"
+
+// DefaultBridgeShutdownTimeout is the default time given to the
+// subscriptions of a Bridge to shut down.
+const DefaultBridgeShutdownTimeout = 10 * time.Second
+
+// Bridge subscribes to channels on a Subscriber and republishes the events
+// to a Publisher, typically one of another backend.
+//
+// An event is acked only after it was published downstream, it is nacked
+// when the transformation or the publish fails. Filtered out events are
+// acked without being published.
+type Bridge[T, P any] struct {
+	subscriber Subscriber[T, P]
+	publisher  Publisher[T, P]
+	opts       bridgeOptions[T, P]
+	metrics    bridgeMetrics
+}
+
+type bridgeOptions[T, P any] struct {
+	logger        *slog.Logger
+	meterProvider metric.MeterProvider
+	mapping       map[string]string
+	filters       []func(Event[T, P]) bool
+	transforms    []func(Event[T, P]) (Event[T, P], error)
+
+	shutdownTimeout time.Duration
+}
+
+// BridgeOption configures a Bridge.
+type BridgeOption[T, P any] func(*bridgeOptions[T, P])
+
+// WithBridgeLogger sets the logger of the bridge. Defaults to
+// slog.Default().
+func WithBridgeLogger[T, P any](logger *slog.Logger) BridgeOption[T, P] {
+	return func(o *bridgeOptions[T, P]) {
+		o.logger = logger
+	}
+}
+
+// WithBridgeMeterProvider sets the provider of the bridge metrics.
+// Defaults to the global OpenTelemetry meter provider.
+func WithBridgeMeterProvider[T, P any](mp metric.MeterProvider) BridgeOption[T, P] {
+	return func(o *bridgeOptions[T, P]) {
+		o.meterProvider = mp
+	}
+}
+
+// WithBridgeShutdownTimeout sets the time given to the subscriptions
+// implementing Shutdowner to forward their in-flight events when Run
+// returns. Defaults to DefaultBridgeShutdownTimeout.
+func WithBridgeShutdownTimeout[T, P any](timeout time.Duration) BridgeOption[T, P] {
+	return func(o *bridgeOptions[T, P]) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithChannelMapping maps the upstream channel names to the downstream
+// ones. Channels missing from mapping keep their name.
+func WithChannelMapping[T, P any](mapping map[string]string) BridgeOption[T, P] {
+	return func(o *bridgeOptions[T, P]) {
+		o.mapping = mapping
+	}
+}
+
+// WithFilter only forwards the events for which keep returns true.
+// Multiple filters must all keep the event.
+func WithFilter[T, P any](keep func(Event[T, P]) bool) BridgeOption[T, P] {
+	return func(o *bridgeOptions[T, P]) {
+		o.filters = append(o.filters, keep)
+	}
+}
+
+// WithTransform changes the events before they are published downstream.
+// Multiple transforms are applied in order. An event is nacked when a
+// transform returns an error.
+func WithTransform[T, P any](transform func(Event[T, P]) (Event[T, P], error)) BridgeOption[T, P] {
+	return func(o *bridgeOptions[T, P]) {
+		o.transforms = append(o.transforms, transform)
+	}
+}
+
+// NewBridge creates a Bridge that forwards the events received by
+// subscriber to publisher.
+func NewBridge[T, P any](
+	subscriber Subscriber[T, P],
+	publisher Publisher[T, P],
+	opts ...BridgeOption[T, P],
+) (*Bridge[T, P], error) {
+	o := bridgeOptions[T, P]{
+		logger:          slog.Default(),
+		meterProvider:   otel.GetMeterProvider(),
+		shutdownTimeout: DefaultBridgeShutdownTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	metrics, err := newBridgeMetrics(o.meterProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bridge[T, P]{
+		subscriber: subscriber,
+		publisher:  publisher,
+		opts:       o,
+		metrics:    metrics,
+	}, nil
+}
+
+// Run subscribes to every channel and forwards the events until ctx is
+// done. The subscriptions are then shut down, gracefully when they
+// implement Shutdowner.
+func (b *Bridge[T, P]) Run(ctx context.Context, channels ...string) error {
+	if len(channels) == 0 {
+		return ErrNoChannel
+	}
+
+	subs := make([]Subscription[T, P], 0, len(channels))
+
+	closeAll := func() error {
+		var errs []error
+
+		for _, sub := range subs {
+			errs = append(errs, closeSubscription(sub, b.opts.shutdownTimeout))
+		}
+
+		return errors.Join(errs...)
+	}
+
+	// Every channel gets its own subscription, so the source channel of
+	// each event is known when mapping it downstream.
+	for _, channel := range channels {
+		sub, err := b.subscriber.Subscribe(channel)
+		if err != nil {
+			return errors.Join(fmt.Errorf("subscribe to %q: %w", channel, err), closeAll())
+		}
+
+		subs = append(subs, sub)
+	}
+
+	var wg sync.WaitGroup
+
+	for i, sub := range subs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			b.forward(ctx, channels[i], sub)
+		}()
+	}
+
+	<-ctx.Done()
+
+	err := closeAll()
+
+	wg.Wait()
+
+	return err
+}
+
+func closeSubscription[T, P any](sub Subscription[T, P], shutdownTimeout time.Duration) error {
+	shutdowner, ok := sub.(Shutdowner)
+	if !ok {
+		return sub.Close()
+	}
+
+	// Give in-flight events a chance to be forwarded.
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return shutdowner.Shutdown(ctx)
+}
+
+func (b *Bridge[T, P]) forward(ctx context.Context, channel string, sub Subscription[T, P]) {
+	downstream := channel
+
+	if mapped, ok := b.opts.mapping[channel]; ok {
+		downstream = mapped
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("channel", channel),
+		attribute.String("downstream_channel", downstream),
+	)
+
+	logger := b.opts.logger.With(
+		slog.String("component", "pubsub.bridge"),
+		slog.String("channel", channel),
+		slog.String("downstream_channel", downstream),
+	)
+
+	for evt := range sub.C() {
+		b.metrics.received.Add(ctx, 1, attrs)
+
+		if evt.Error != nil {
+			logger.Error("upstream error", slog.String("error", evt.Error.Error()))
+			b.metrics.failed.Add(ctx, 1, attrs)
+			evt.Nack()
+
+			continue
+		}
+
+		if !b.keep(evt) {
+			b.metrics.filtered.Add(ctx, 1, attrs)
+			evt.Ack()
+
+			continue
+		}
+
+		out, err := b.transform(evt)
+		if err != nil {
+			logger.Error("transform event", slog.String("error", err.Error()))
+			b.metrics.failed.Add(ctx, 1, attrs)
+			evt.Nack()
+
+			continue
+		}
+
+		// The acknowledgement belongs to the upstream backend.
+		out.Acker = nil
+
+		start := time.Now()
+
+		err = b.publisher.Publish(out, downstream)
+
+		b.metrics.publishDuration.Record(ctx, time.Since(start).Seconds(), attrs)
+
+		if err != nil {
+			logger.Error("publish event", slog.String("error", err.Error()))
+			b.metrics.failed.Add(ctx, 1, attrs)
+			evt.Nack()
+
+			continue
+		}
+
+		b.metrics.forwarded.Add(ctx, 1, attrs)
+		evt.Ack()
+	}
+}
+
+func (b *Bridge[T, P]) keep(evt Event[T, P]) bool {
+	for _, keep := range b.opts.filters {
+		if !keep(evt) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *Bridge[T, P]) transform(evt Event[T, P]) (Event[T, P], error) {
+	for _, transform := range b.opts.transforms {
+		var err error
+
+		evt, err = transform(evt)
+		if err != nil {
+			return Event[T, P]{}, err
+		}
+	}
+
+	return evt, nil
+}
+
+type bridgeMetrics struct {
+	received        metric.Int64Counter
+	forwarded       metric.Int64Counter
+	filtered        metric.Int64Counter
+	failed          metric.Int64Counter
+	publishDuration metric.Float64Histogram
+}
+
+func newBridgeMetrics(mp metric.MeterProvider) (bridgeMetrics, error) {
+	meter := mp.Meter(bridgeInstrumentationName)
+
+	var (
+		m    bridgeMetrics
+		errs = make([]error, 5)
+	)
+
+	m.received, errs[0] = meter.Int64Counter(
+		"pubsub.bridge.events.received",
+		metric.WithDescription("Events received from the upstream subscriber."),
+	)
+	m.forwarded, errs[1] = meter.Int64Counter(
+		"pubsub.bridge.events.forwarded",
+		metric.WithDescription("Events published downstream and acked upstream."),
+	)
+	m.filtered, errs[2] = meter.Int64Counter(
+		"pubsub.bridge.events.filtered",
+		metric.WithDescription("Events dropped by a filter."),
+	)
+	m.failed, errs[3] = meter.Int64Counter(
+		"pubsub.bridge.events.failed",
+		metric.WithDescription("Events nacked because of an error."),
+	)
+	m.publishDuration, errs[4] = meter.Float64Histogram(
+		"pubsub.bridge.publish.duration",
+		metric.WithDescription("Duration of the downstream publish."),
+		metric.WithUnit("s"),
+	)
+
+	if err := errors.Join(errs...); err != nil {
+		return bridgeMetrics{}, fmt.Errorf("create bridge metrics: %w", err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,229 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/pubsub"
+	"github.com/purposeinplay/go-commons/pubsub/inmem"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// ackingSubscriber attaches a countingAcker to every event.
+type ackingSubscriber struct {
+	pubsub.Subscriber[string, string]
+	acker *countingAcker
+}
+
+func (s ackingSubscriber) Subscribe(channels ...string) (pubsub.Subscription[string, string], error) {
+	sub, err := s.Subscriber.Subscribe(channels...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan pubsub.Event[string, string])
+
+	go func() {
+		defer close(out)
+
+		for evt := range sub.C() {
+			evt.Acker = s.acker
+			out <- evt
+		}
+	}()
+
+	return ackingSubscription{Subscription: sub, c: out}, nil
+}
+
+type ackingSubscription struct {
+	pubsub.Subscription[string, string]
+	c chan pubsub.Event[string, string]
+}
+
+func (s ackingSubscription) C() <-chan pubsub.Event[string, string] { return s.c }
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(pubsub.Event[string, string], ...string) error {
+	return errors.New("broker down")
+}
+
+func TestBridge(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	var (
+		upstream   = inmem.NewPubSub[string, string](10)
+		downstream = inmem.NewPubSub[string, string](10)
+		acker      = &countingAcker{}
+		reader     = sdkmetric.NewManualReader()
+	)
+
+	bridge, err := pubsub.NewBridge[string, string](
+		ackingSubscriber{Subscriber: upstream, acker: acker},
+		downstream,
+		pubsub.WithChannelMapping[string, string](map[string]string{"users": "kafka.users"}),
+		pubsub.WithFilter(func(evt pubsub.Event[string, string]) bool {
+			return evt.Type != "internal"
+		}),
+		pubsub.WithTransform(func(evt pubsub.Event[string, string]) (pubsub.Event[string, string], error) {
+			evt.Payload = strings.ToUpper(evt.Payload)
+			return evt, nil
+		}),
+		pubsub.WithBridgeMeterProvider[string, string](
+			sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		),
+	)
+	is.NoErr(err)
+
+	sub, err := downstream.Subscribe("kafka.users")
+	is.NoErr(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runErr := make(chan error, 1)
+
+	go func() { runErr <- bridge.Run(ctx, "users") }()
+
+	// Wait for the bridge to subscribe upstream.
+	time.Sleep(20 * time.Millisecond)
+
+	is.NoErr(upstream.Publish(pubsub.Event[string, string]{Type: "internal", Payload: "skip"}, "users"))
+	is.NoErr(upstream.Publish(pubsub.Event[string, string]{Type: "user_created", Payload: "john"}, "users"))
+
+	select {
+	case evt := <-sub.C():
+		is.Equal(evt.Type, "user_created")
+		is.Equal(evt.Payload, "JOHN")
+		is.Equal(evt.Acker, nil)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for bridged event")
+	}
+
+	cancel()
+	is.NoErr(<-runErr)
+
+	acker.mu.Lock()
+	is.Equal(acker.acks, 2)
+	is.Equal(acker.nacks, 0)
+	acker.mu.Unlock()
+
+	var rm metricdata.ResourceMetrics
+
+	is.NoErr(reader.Collect(context.Background(), &rm))
+
+	counts := map[string]int64{}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range sum.DataPoints {
+					counts[m.Name] += dp.Value
+				}
+			}
+		}
+	}
+
+	is.Equal(counts["pubsub.bridge.events.received"], int64(2))
+	is.Equal(counts["pubsub.bridge.events.filtered"], int64(1))
+	is.Equal(counts["pubsub.bridge.events.forwarded"], int64(1))
+}
+
+func TestBridgeNacksWhenPublishFails(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	var (
+		upstream = inmem.NewPubSub[string, string](10)
+		acker    = &countingAcker{}
+	)
+
+	bridge, err := pubsub.NewBridge[string, string](
+		ackingSubscriber{Subscriber: upstream, acker: acker},
+		failingPublisher{},
+	)
+	is.NoErr(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runErr := make(chan error, 1)
+
+	go func() { runErr <- bridge.Run(ctx, "users") }()
+
+	time.Sleep(20 * time.Millisecond)
+
+	is.NoErr(upstream.Publish(pubsub.Event[string, string]{Type: "user_created"}, "users"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	is.NoErr(<-runErr)
+
+	acker.mu.Lock()
+	defer acker.mu.Unlock()
+
+	is.Equal(acker.acks, 0)
+	is.Equal(acker.nacks, 1)
+}
+
+// shutdownSubscriber returns subscriptions recording the time left to
+// shut them down.
+type shutdownSubscriber struct {
+	pubsub.Subscriber[string, string]
+	timeouts chan time.Duration
+}
+
+func (s shutdownSubscriber) Subscribe(channels ...string) (pubsub.Subscription[string, string], error) {
+	sub, err := s.Subscriber.Subscribe(channels...)
+	if err != nil {
+		return nil, err
+	}
+
+	return shutdownSubscription{Subscription: sub, timeouts: s.timeouts}, nil
+}
+
+type shutdownSubscription struct {
+	pubsub.Subscription[string, string]
+	timeouts chan time.Duration
+}
+
+func (s shutdownSubscription) Shutdown(ctx context.Context) error {
+	deadline, _ := ctx.Deadline()
+	s.timeouts <- time.Until(deadline)
+
+	return s.Close()
+}
+
+func TestBridgeShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	timeouts := make(chan time.Duration, 1)
+
+	bridge, err := pubsub.NewBridge[string, string](
+		shutdownSubscriber{Subscriber: inmem.NewPubSub[string, string](10), timeouts: timeouts},
+		inmem.NewPubSub[string, string](10),
+		pubsub.WithBridgeShutdownTimeout[string, string](time.Minute),
+	)
+	is.NoErr(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	is.NoErr(bridge.Run(ctx, "users"))
+
+	timeout := <-timeouts
+	is.True(timeout > pubsub.DefaultBridgeShutdownTimeout)
+	is.True(timeout <= time.Minute)
+}
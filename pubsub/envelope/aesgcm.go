@@ -0,0 +1,98 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+)
+
+var _ Codec = AESGCMCodec{}
+
+// AESGCMCodec encrypts payloads with AES-GCM.
+//
+// The sealed payload is the random nonce followed by the ciphertext. The
+// key ID is authenticated as additional data, so a payload cannot be
+// replayed under another key ID.
+type AESGCMCodec struct {
+	keys keyRing
+}
+
+// NewAESGCMCodec builds a codec from a key ring. primaryKey is used for
+// encryption. primaryKey plus fallbackKeys are used for decryption. Every
+// secret must be 16, 24 or 32 bytes long to select AES-128, AES-192 or
+// AES-256.
+func NewAESGCMCodec(primaryKey Key, fallbackKeys ...Key) (AESGCMCodec, error) {
+	keys, err := newKeyRing(primaryKey, fallbackKeys)
+	if err != nil {
+		return AESGCMCodec{}, err
+	}
+
+	for _, key := range keys.byID {
+		if _, err := aes.NewCipher(key.Secret); err != nil {
+			return AESGCMCodec{}, fmt.Errorf("%w: key %q: %v", ErrInvalidConfig, key.ID, err)
+		}
+	}
+
+	return AESGCMCodec{keys: keys}, nil
+}
+
+// Seal encrypts payload with the primary key.
+func (c AESGCMCodec) Seal(payload []byte) ([]byte, map[string]string, error) {
+	key := c.keys.primary
+
+	aead, err := newGCM(key.Secret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, payload, []byte(key.ID))
+
+	return sealed, map[string]string{HeaderKeyID: key.ID}, nil
+}
+
+// Open decrypts payload with the key referenced by the HeaderKeyID header.
+func (c AESGCMCodec) Open(payload []byte, headers map[string]string) ([]byte, error) {
+	key, err := c.keys.lookup(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(key.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: payload shorter than nonce", ErrMalformedPayload)
+	}
+
+	nonce, ciphertext := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(key.ID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(secret []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, fmt.Errorf("new cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("new gcm: %w", err)
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,285 @@
+// Package envelope wraps pubsub publishers and subscribers with a Codec
+// that seals event payloads before they reach the broker and opens them
+// again on the subscriber side.
+//
+// Two codecs are provided: an AES-GCM codec for end-to-end encryption and
+// an HMAC codec for integrity only. Both support key rotation, the ID of
+// the key used to seal a payload travels in the HeaderKeyID header.
+package envelope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/purposeinplay/go-commons/pubsub"
+)
+
+// Headers set on the sealed events.
+const (
+	// HeaderKeyID carries the ID of the key used to seal the payload.
+	HeaderKeyID = "envelope-key-id"
+	// HeaderSignature carries the hex encoded HMAC of the payload.
+	HeaderSignature = "envelope-signature"
+)
+
+var (
+	// ErrInvalidConfig is returned when a codec is built with invalid keys.
+	ErrInvalidConfig = errors.New("invalid envelope config")
+
+	// ErrUnknownKeyID is returned when the key ID header of an event does
+	// not match any of the configured keys.
+	ErrUnknownKeyID = errors.New("unknown envelope key id")
+
+	// ErrMalformedPayload is returned when a sealed event does not have the
+	// expected format.
+	ErrMalformedPayload = errors.New("malformed envelope payload")
+
+	// ErrInvalidSignature is returned when signature verification fails.
+	ErrInvalidSignature = errors.New("invalid envelope signature")
+
+	// ErrDecrypt is returned when a payload cannot be decrypted.
+	ErrDecrypt = errors.New("envelope decrypt failed")
+)
+
+// Key is a secret identified by an ID. The ID is sent along with the
+// sealed events so subscribers know which key to use.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Codec seals and opens event payloads.
+type Codec interface {
+	// Seal returns the sealed payload and the headers that must be sent
+	// along with it.
+	Seal(payload []byte) ([]byte, map[string]string, error)
+
+	// Open verifies and returns the original payload.
+	Open(payload []byte, headers map[string]string) ([]byte, error)
+}
+
+// keyRing holds the primary key, used for sealing, and the fallback keys
+// that are still accepted when opening.
+type keyRing struct {
+	primary Key
+	byID    map[string]Key
+}
+
+func newKeyRing(primary Key, fallbackKeys []Key) (keyRing, error) {
+	if primary.ID == "" {
+		return keyRing{}, fmt.Errorf("%w: primary key id is required", ErrInvalidConfig)
+	}
+
+	if len(primary.Secret) == 0 {
+		return keyRing{}, fmt.Errorf("%w: primary key is required", ErrInvalidConfig)
+	}
+
+	byID := make(map[string]Key, 1+len(fallbackKeys))
+	byID[primary.ID] = primary
+
+	for i, key := range fallbackKeys {
+		if key.ID == "" {
+			return keyRing{}, fmt.Errorf("%w: fallback key %d id is required", ErrInvalidConfig, i)
+		}
+
+		if len(key.Secret) == 0 {
+			return keyRing{}, fmt.Errorf("%w: fallback key %d is empty", ErrInvalidConfig, i)
+		}
+
+		if _, ok := byID[key.ID]; ok {
+			return keyRing{}, fmt.Errorf("%w: duplicate key id %q", ErrInvalidConfig, key.ID)
+		}
+
+		byID[key.ID] = key
+	}
+
+	return keyRing{
+		primary: primary,
+		byID:    byID,
+	}, nil
+}
+
+func (r keyRing) lookup(headers map[string]string) (Key, error) {
+	keyID, ok := headers[HeaderKeyID]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: missing %s header", ErrMalformedPayload, HeaderKeyID)
+	}
+
+	key, ok := r.byID[keyID]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: %q", ErrUnknownKeyID, keyID)
+	}
+
+	return key, nil
+}
+
+var _ pubsub.Publisher[string, []byte] = (*Publisher)(nil)
+
+// Publisher seals the payload of the events before publishing them.
+type Publisher struct {
+	publisher pubsub.Publisher[string, []byte]
+	codec     Codec
+}
+
+// NewPublisher wraps publisher so every event is sealed with codec.
+func NewPublisher(publisher pubsub.Publisher[string, []byte], codec Codec) *Publisher {
+	return &Publisher{
+		publisher: publisher,
+		codec:     codec,
+	}
+}
+
+// Publish seals the event payload and publishes it to the given channels.
+func (p *Publisher) Publish(event pubsub.Event[string, []byte], channels ...string) error {
+	payload, sealHeaders, err := p.codec.Seal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("seal payload: %w", err)
+	}
+
+	headers := make(map[string]string, len(event.Headers)+len(sealHeaders))
+
+	for k, v := range event.Headers {
+		headers[k] = v
+	}
+
+	for k, v := range sealHeaders {
+		headers[k] = v
+	}
+
+	event.Payload = payload
+	event.Headers = headers
+
+	return p.publisher.Publish(event, channels...)
+}
+
+var _ pubsub.Subscriber[string, []byte] = (*Subscriber)(nil)
+
+// Subscriber opens the payload of the events received by the wrapped
+// subscriber.
+type Subscriber struct {
+	subscriber pubsub.Subscriber[string, []byte]
+	codec      Codec
+}
+
+// NewSubscriber wraps subscriber so every event is opened with codec.
+func NewSubscriber(subscriber pubsub.Subscriber[string, []byte], codec Codec) *Subscriber {
+	return &Subscriber{
+		subscriber: subscriber,
+		codec:      codec,
+	}
+}
+
+// Subscribe creates a new subscription on the wrapped subscriber.
+//
+// Events that cannot be opened are delivered as pubsub.EventTypeError
+// events. They keep the Acker of the original event, so the application
+// decides whether to ack or nack them.
+func (s *Subscriber) Subscribe(channels ...string) (pubsub.Subscription[string, []byte], error) {
+	sub, err := s.subscriber.Subscribe(channels...)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &Subscription{
+		subscription: sub,
+		codec:        s.codec,
+		eventCh:      make(chan pubsub.Event[string, []byte]),
+		closing:      make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	go subscription.forward()
+
+	return subscription, nil
+}
+
+var (
+	_ pubsub.Subscription[string, []byte] = (*Subscription)(nil)
+	_ pubsub.Shutdowner                   = (*Subscription)(nil)
+)
+
+// Subscription is a stream of opened events.
+type Subscription struct {
+	subscription pubsub.Subscription[string, []byte]
+	codec        Codec
+
+	eventCh chan pubsub.Event[string, []byte]
+	closing chan struct{}
+	done    chan struct{}
+
+	closingOnce sync.Once
+}
+
+func (s *Subscription) forward() {
+	defer close(s.done)
+	defer close(s.eventCh)
+
+	for evt := range s.subscription.C() {
+		evt = s.open(evt)
+
+		select {
+		case s.eventCh <- evt:
+		case <-s.closing:
+			evt.Nack()
+
+			return
+		}
+	}
+}
+
+func (s *Subscription) open(evt pubsub.Event[string, []byte]) pubsub.Event[string, []byte] {
+	if evt.Error != nil {
+		return evt
+	}
+
+	payload, err := s.codec.Open(evt.Payload, evt.Headers)
+	if err != nil {
+		return pubsub.Event[string, []byte]{
+			Type:    pubsub.EventTypeError,
+			Error:   fmt.Errorf("open %q event: %w", evt.Type, err),
+			Headers: evt.Headers,
+			Acker:   evt.Acker,
+		}
+	}
+
+	evt.Payload = payload
+
+	return evt
+}
+
+// C returns a receive-only go channel of opened events.
+func (s *Subscription) C() <-chan pubsub.Event[string, []byte] {
+	return s.eventCh
+}
+
+// Close closes the wrapped subscription.
+func (s *Subscription) Close() error {
+	s.closingOnce.Do(func() { close(s.closing) })
+
+	err := s.subscription.Close()
+
+	<-s.done
+
+	return err
+}
+
+// Shutdown gracefully closes the wrapped subscription when it implements
+// pubsub.Shutdowner, otherwise it behaves like Close.
+func (s *Subscription) Shutdown(ctx context.Context) error {
+	shutdowner, ok := s.subscription.(pubsub.Shutdowner)
+	if !ok {
+		return s.Close()
+	}
+
+	// Stop handing new events to the application, events that were not
+	// delivered yet are nacked so the wrapped subscription can drain.
+	s.closingOnce.Do(func() { close(s.closing) })
+
+	err := shutdowner.Shutdown(ctx)
+
+	<-s.done
+
+	return err
+}
@@ -0,0 +1,177 @@
+package envelope_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/pubsub"
+	"github.com/purposeinplay/go-commons/pubsub/envelope"
+	"github.com/purposeinplay/go-commons/pubsub/inmem"
+)
+
+var (
+	oldKey = envelope.Key{ID: "2024", Secret: bytes.Repeat([]byte{1}, 32)}
+	newKey = envelope.Key{ID: "2025", Secret: bytes.Repeat([]byte{2}, 32)}
+)
+
+func TestAESGCMCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	codec, err := envelope.NewAESGCMCodec(newKey)
+	is.NoErr(err)
+
+	ps := inmem.NewPubSub[string, []byte](1)
+
+	sub, err := envelope.NewSubscriber(ps, codec).Subscribe("users")
+	is.NoErr(err)
+
+	t.Cleanup(func() { is.NoErr(sub.Close()) })
+
+	// Subscribe to the raw channel to check the payload is encrypted.
+	rawSub, err := ps.Subscribe("users")
+	is.NoErr(err)
+
+	err = envelope.NewPublisher(ps, codec).Publish(pubsub.Event[string, []byte]{
+		Type:    "user_created",
+		Payload: []byte("john@example.com"),
+		Headers: map[string]string{"request-id": "1"},
+	}, "users")
+	is.NoErr(err)
+
+	raw := <-rawSub.C()
+	is.True(!bytes.Contains(raw.Payload, []byte("john@example.com")))
+	is.Equal(raw.Headers[envelope.HeaderKeyID], newKey.ID)
+
+	evt := <-sub.C()
+	is.NoErr(evt.Error)
+	is.Equal(evt.Type, "user_created")
+	is.Equal(evt.Payload, []byte("john@example.com"))
+	is.Equal(evt.Headers["request-id"], "1")
+}
+
+func TestAESGCMCodecKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	oldCodec, err := envelope.NewAESGCMCodec(oldKey)
+	is.NoErr(err)
+
+	rotatedCodec, err := envelope.NewAESGCMCodec(newKey, oldKey)
+	is.NoErr(err)
+
+	payload, headers, err := oldCodec.Seal([]byte("secret"))
+	is.NoErr(err)
+
+	got, err := rotatedCodec.Open(payload, headers)
+	is.NoErr(err)
+	is.Equal(got, []byte("secret"))
+
+	// A codec that dropped the old key rejects the payload.
+	newCodec, err := envelope.NewAESGCMCodec(newKey)
+	is.NoErr(err)
+
+	_, err = newCodec.Open(payload, headers)
+	is.True(errors.Is(err, envelope.ErrUnknownKeyID))
+}
+
+func TestAESGCMCodecTamperDetection(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	codec, err := envelope.NewAESGCMCodec(newKey, oldKey)
+	is.NoErr(err)
+
+	payload, headers, err := codec.Seal([]byte("secret"))
+	is.NoErr(err)
+
+	payload[len(payload)-1] ^= 0xff
+
+	_, err = codec.Open(payload, headers)
+	is.True(errors.Is(err, envelope.ErrDecrypt))
+
+	// The key ID is authenticated, switching it fails the decryption.
+	payload[len(payload)-1] ^= 0xff
+	headers[envelope.HeaderKeyID] = oldKey.ID
+
+	_, err = codec.Open(payload, headers)
+	is.True(errors.Is(err, envelope.ErrDecrypt))
+}
+
+func TestNewAESGCMCodecInvalidKey(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	_, err := envelope.NewAESGCMCodec(envelope.Key{ID: "1", Secret: []byte("short")})
+	is.True(errors.Is(err, envelope.ErrInvalidConfig))
+
+	_, err = envelope.NewAESGCMCodec(newKey, envelope.Key{ID: newKey.ID, Secret: oldKey.Secret})
+	is.True(errors.Is(err, envelope.ErrInvalidConfig))
+}
+
+func TestHMACCodec(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	oldCodec, err := envelope.NewHMACCodec(oldKey)
+	is.NoErr(err)
+
+	codec, err := envelope.NewHMACCodec(newKey, oldKey)
+	is.NoErr(err)
+
+	payload, headers, err := oldCodec.Seal([]byte("hello"))
+	is.NoErr(err)
+	is.Equal(payload, []byte("hello"))
+
+	got, err := codec.Open(payload, headers)
+	is.NoErr(err)
+	is.Equal(got, []byte("hello"))
+
+	_, err = codec.Open([]byte("hellO"), headers)
+	is.True(errors.Is(err, envelope.ErrInvalidSignature))
+
+	delete(headers, envelope.HeaderSignature)
+
+	_, err = codec.Open(payload, headers)
+	is.True(errors.Is(err, envelope.ErrMalformedPayload))
+}
+
+func TestSubscriberSurfacesErrorEvents(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	codec, err := envelope.NewHMACCodec(newKey)
+	is.NoErr(err)
+
+	ps := inmem.NewPubSub[string, []byte](1)
+
+	sub, err := envelope.NewSubscriber(ps, codec).Subscribe("users")
+	is.NoErr(err)
+
+	t.Cleanup(func() { is.NoErr(sub.Close()) })
+
+	// Publish an unsigned event.
+	err = ps.Publish(pubsub.Event[string, []byte]{
+		Type:    "user_created",
+		Payload: []byte("hello"),
+	}, "users")
+	is.NoErr(err)
+
+	evt := <-sub.C()
+	is.Equal(evt.Type, pubsub.EventTypeError)
+	is.True(errors.Is(evt.Error, envelope.ErrMalformedPayload))
+}
@@ -0,0 +1,76 @@
+package envelope
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+var _ Codec = HMACCodec{}
+
+// HMACCodec signs payloads with HMAC-SHA256.
+//
+// This codec provides integrity (tamper detection), not confidentiality.
+// The payload travels in clear text, the signature travels in the
+// HeaderSignature header.
+type HMACCodec struct {
+	keys keyRing
+}
+
+// NewHMACCodec builds a codec from a key ring. primaryKey is used for
+// signing. primaryKey plus fallbackKeys are used for verification.
+func NewHMACCodec(primaryKey Key, fallbackKeys ...Key) (HMACCodec, error) {
+	keys, err := newKeyRing(primaryKey, fallbackKeys)
+	if err != nil {
+		return HMACCodec{}, err
+	}
+
+	return HMACCodec{keys: keys}, nil
+}
+
+// Seal signs payload with the primary key. The payload is returned as is.
+func (c HMACCodec) Seal(payload []byte) ([]byte, map[string]string, error) {
+	key := c.keys.primary
+
+	return payload, map[string]string{
+		HeaderKeyID:     key.ID,
+		HeaderSignature: hex.EncodeToString(signBytes(key, payload)),
+	}, nil
+}
+
+// Open verifies the signature of payload with the key referenced by the
+// HeaderKeyID header.
+func (c HMACCodec) Open(payload []byte, headers map[string]string) ([]byte, error) {
+	key, err := c.keys.lookup(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, ok := headers[HeaderSignature]
+	if !ok || signature == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrMalformedPayload, HeaderSignature)
+	}
+
+	sigBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature is not valid hex", ErrMalformedPayload)
+	}
+
+	if !hmac.Equal(signBytes(key, payload), sigBytes) {
+		return nil, ErrInvalidSignature
+	}
+
+	return payload, nil
+}
+
+// signBytes binds the key ID to the signature, so a signature cannot be
+// replayed under another key ID.
+func signBytes(key Key, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key.Secret)
+	_, _ = mac.Write([]byte(key.ID))
+	_, _ = mac.Write([]byte{0})
+	_, _ = mac.Write(payload)
+
+	return mac.Sum(nil)
+}
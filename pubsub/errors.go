@@ -6,3 +6,6 @@ import (
 
 // ErrExactlyOneChannelAllowed is returned a pubsub implementation supports only one channel.
 var ErrExactlyOneChannelAllowed = errors.New("exactly one channel allowed")
+
+// ErrNoChannel is returned when no channels are given.
+var ErrNoChannel = errors.New("no channel given")
@@ -0,0 +1,243 @@
+// Package flowcontrol wraps pubsub subscriptions with controls over the
+// delivery of events: pause, resume and a token-bucket rate limit.
+//
+// The controls can be changed while the subscription is running, either
+// from code or through the admin HTTP handler returned by NewHandler.
+package flowcontrol
+
+import (
+	"context"
+	"sync"
+
+	"github.com/purposeinplay/go-commons/pubsub"
+	"golang.org/x/time/rate"
+)
+
+// Controller is implemented by subscriptions whose delivery can be
+// controlled at runtime.
+type Controller interface {
+	pubsub.Pauser
+
+	// SetRateLimit limits the delivery to eventsPerSecond, allowing bursts
+	// of up to burst events. A non-positive eventsPerSecond removes the
+	// limit.
+	SetRateLimit(eventsPerSecond float64, burst int)
+
+	// RateLimit returns the current limit, eventsPerSecond is 0 when the
+	// delivery is not limited.
+	RateLimit() (eventsPerSecond float64, burst int)
+}
+
+type options struct {
+	eventsPerSecond float64
+	burst           int
+}
+
+// Option configures a Subscription.
+type Option func(*options)
+
+// WithRateLimit limits the delivery to eventsPerSecond, allowing bursts of
+// up to burst events. The delivery is not limited by default.
+func WithRateLimit(eventsPerSecond float64, burst int) Option {
+	return func(o *options) {
+		o.eventsPerSecond = eventsPerSecond
+		o.burst = burst
+	}
+}
+
+var (
+	_ pubsub.Subscription[string, any] = (*Subscription[string, any])(nil)
+	_ pubsub.Shutdowner                = (*Subscription[string, any])(nil)
+	_ Controller                       = (*Subscription[string, any])(nil)
+)
+
+// Subscription delivers the events of the wrapped subscription, holding
+// them back while paused or when the rate limit is exceeded.
+type Subscription[T, P any] struct {
+	subscription pubsub.Subscription[T, P]
+	limiter      *rate.Limiter
+
+	eventCh chan pubsub.Event[T, P]
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu sync.Mutex
+	// resumed is closed while the subscription is not paused.
+	resumed chan struct{}
+	paused  bool
+}
+
+// New wraps subscription with flow controls.
+func New[T, P any](subscription pubsub.Subscription[T, P], opts ...Option) *Subscription[T, P] {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	resumed := make(chan struct{})
+	close(resumed)
+
+	s := &Subscription[T, P]{
+		subscription: subscription,
+		limiter:      rate.NewLimiter(rate.Inf, 0),
+		eventCh:      make(chan pubsub.Event[T, P]),
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+		resumed:      resumed,
+	}
+
+	s.SetRateLimit(o.eventsPerSecond, o.burst)
+
+	go s.forward()
+
+	return s
+}
+
+func (s *Subscription[T, P]) forward() {
+	defer close(s.done)
+	defer close(s.eventCh)
+
+	for evt := range s.subscription.C() {
+		if err := s.waitResumed(); err != nil {
+			evt.Nack()
+			return
+		}
+
+		if err := s.limiter.Wait(s.ctx); err != nil {
+			evt.Nack()
+			return
+		}
+
+		select {
+		case s.eventCh <- evt:
+		case <-s.ctx.Done():
+			evt.Nack()
+			return
+		}
+	}
+}
+
+func (s *Subscription[T, P]) waitResumed() error {
+	s.mu.Lock()
+	resumed := s.resumed
+	s.mu.Unlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// C returns a receive-only go channel of events.
+func (s *Subscription[T, P]) C() <-chan pubsub.Event[T, P] {
+	return s.eventCh
+}
+
+// Close closes the wrapped subscription. The event held back by the
+// controls, if any, is nacked.
+func (s *Subscription[T, P]) Close() error {
+	s.cancel()
+
+	err := s.subscription.Close()
+
+	<-s.done
+
+	return err
+}
+
+// Shutdown gracefully closes the wrapped subscription when it implements
+// pubsub.Shutdowner, otherwise it behaves like Close.
+func (s *Subscription[T, P]) Shutdown(ctx context.Context) error {
+	shutdowner, ok := s.subscription.(pubsub.Shutdowner)
+	if !ok {
+		return s.Close()
+	}
+
+	s.cancel()
+
+	err := shutdowner.Shutdown(ctx)
+
+	<-s.done
+
+	return err
+}
+
+// Pause holds back the delivery of events. When the wrapped subscription
+// implements pubsub.Pauser it is paused as well, so it stops fetching.
+func (s *Subscription[T, P]) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused {
+		return
+	}
+
+	s.paused = true
+	s.resumed = make(chan struct{})
+
+	if pauser, ok := s.subscription.(pubsub.Pauser); ok {
+		pauser.Pause()
+	}
+}
+
+// Resume resumes the delivery of events.
+func (s *Subscription[T, P]) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.paused {
+		return
+	}
+
+	s.paused = false
+	close(s.resumed)
+
+	if pauser, ok := s.subscription.(pubsub.Pauser); ok {
+		pauser.Resume()
+	}
+}
+
+// Paused reports whether the delivery is paused.
+func (s *Subscription[T, P]) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.paused
+}
+
+// SetRateLimit limits the delivery to eventsPerSecond, allowing bursts of
+// up to burst events. A non-positive eventsPerSecond removes the limit.
+// The burst is at least 1 when the delivery is limited.
+func (s *Subscription[T, P]) SetRateLimit(eventsPerSecond float64, burst int) {
+	if eventsPerSecond <= 0 {
+		s.limiter.SetLimit(rate.Inf)
+		s.limiter.SetBurst(0)
+
+		return
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	s.limiter.SetBurst(burst)
+	s.limiter.SetLimit(rate.Limit(eventsPerSecond))
+}
+
+// RateLimit returns the current limit, eventsPerSecond is 0 when the
+// delivery is not limited.
+func (s *Subscription[T, P]) RateLimit() (eventsPerSecond float64, burst int) {
+	limit := s.limiter.Limit()
+	if limit == rate.Inf {
+		return 0, 0
+	}
+
+	return float64(limit), s.limiter.Burst()
+}
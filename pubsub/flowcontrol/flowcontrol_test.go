@@ -0,0 +1,142 @@
+package flowcontrol_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/pubsub"
+	"github.com/purposeinplay/go-commons/pubsub/flowcontrol"
+	"github.com/purposeinplay/go-commons/pubsub/inmem"
+)
+
+func TestSubscriptionPauseResume(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	ps := inmem.NewPubSub[string, string](10)
+
+	inner, err := ps.Subscribe("a")
+	is.NoErr(err)
+
+	sub := flowcontrol.New(inner)
+
+	t.Cleanup(func() { is.NoErr(sub.Close()) })
+
+	sub.Pause()
+	is.True(sub.Paused())
+	is.True(inner.(pubsub.Pauser).Paused())
+
+	is.NoErr(ps.Publish(pubsub.Event[string, string]{Type: "test", Payload: "1"}, "a"))
+
+	select {
+	case <-sub.C():
+		t.Fatal("expected no event while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	sub.Resume()
+
+	select {
+	case evt := <-sub.C():
+		is.Equal(evt.Payload, "1")
+	case <-time.After(time.Second):
+		t.Fatal("expected event after resume")
+	}
+}
+
+func TestSubscriptionRateLimit(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	ps := inmem.NewPubSub[string, string](10)
+
+	inner, err := ps.Subscribe("a")
+	is.NoErr(err)
+
+	sub := flowcontrol.New(inner, flowcontrol.WithRateLimit(20, 1))
+
+	t.Cleanup(func() { is.NoErr(sub.Close()) })
+
+	eventsPerSecond, burst := sub.RateLimit()
+	is.Equal(eventsPerSecond, 20.0)
+	is.Equal(burst, 1)
+
+	for range 3 {
+		is.NoErr(ps.Publish(pubsub.Event[string, string]{Type: "test"}, "a"))
+	}
+
+	start := time.Now()
+
+	for range 3 {
+		<-sub.C()
+	}
+
+	// The first event uses the burst, the next two wait 50ms each.
+	is.True(time.Since(start) >= 90*time.Millisecond)
+
+	sub.SetRateLimit(0, 0)
+
+	eventsPerSecond, _ = sub.RateLimit()
+	is.Equal(eventsPerSecond, 0.0)
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	ps := inmem.NewPubSub[string, string](10)
+
+	inner, err := ps.Subscribe("a")
+	is.NoErr(err)
+
+	sub := flowcontrol.New(inner)
+
+	t.Cleanup(func() { is.NoErr(sub.Close()) })
+
+	h := flowcontrol.NewHandler(map[string]flowcontrol.Controller{"payments": sub})
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/subscriptions/payments/pause", "")
+	is.Equal(rec.Code, http.StatusOK)
+	is.True(sub.Paused())
+
+	rec = do(http.MethodPut, "/subscriptions/payments/rate-limit", `{"events_per_second": 5, "burst": 2}`)
+	is.Equal(rec.Code, http.StatusOK)
+	is.Equal(
+		strings.TrimSpace(rec.Body.String()),
+		`{"name":"payments","paused":true,"events_per_second":5,"burst":2}`,
+	)
+
+	rec = do(http.MethodPost, "/subscriptions/payments/resume", "")
+	is.Equal(rec.Code, http.StatusOK)
+	is.True(!sub.Paused())
+
+	rec = do(http.MethodGet, "/subscriptions", "")
+	is.Equal(rec.Code, http.StatusOK)
+	is.Equal(
+		strings.TrimSpace(rec.Body.String()),
+		`[{"name":"payments","paused":false,"events_per_second":5,"burst":2}]`,
+	)
+
+	rec = do(http.MethodPost, "/subscriptions/unknown/pause", "")
+	is.Equal(rec.Code, http.StatusNotFound)
+
+	rec = do(http.MethodPut, "/subscriptions/payments/rate-limit", `{`)
+	is.Equal(rec.Code, http.StatusBadRequest)
+}
@@ -0,0 +1,130 @@
+package flowcontrol
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// Status is the state of a controlled subscription, as returned by the
+// admin handler.
+type Status struct {
+	Name            string  `json:"name"`
+	Paused          bool    `json:"paused"`
+	EventsPerSecond float64 `json:"events_per_second"`
+	Burst           int     `json:"burst"`
+}
+
+// RateLimitRequest is the body of a rate-limit update.
+// A non-positive EventsPerSecond removes the limit.
+type RateLimitRequest struct {
+	EventsPerSecond float64 `json:"events_per_second"`
+	Burst           int     `json:"burst"`
+}
+
+// NewHandler returns an admin HTTP handler that drives the controllers,
+// identified by name. It serves:
+//
+//	GET  /subscriptions                   lists the status of all controllers
+//	GET  /subscriptions/{name}            returns the status of a controller
+//	POST /subscriptions/{name}/pause      pauses the delivery
+//	POST /subscriptions/{name}/resume     resumes the delivery
+//	PUT  /subscriptions/{name}/rate-limit sets the rate limit from a RateLimitRequest
+//
+// Mount it under an admin prefix with http.StripPrefix.
+func NewHandler(controllers map[string]Controller) http.Handler {
+	h := handler{controllers: controllers}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /subscriptions", h.list)
+	mux.HandleFunc("GET /subscriptions/{name}", h.withController(h.get))
+	mux.HandleFunc("POST /subscriptions/{name}/pause", h.withController(h.pause))
+	mux.HandleFunc("POST /subscriptions/{name}/resume", h.withController(h.resume))
+	mux.HandleFunc("PUT /subscriptions/{name}/rate-limit", h.withController(h.setRateLimit))
+
+	return mux
+}
+
+type handler struct {
+	controllers map[string]Controller
+}
+
+type controllerHandlerFunc func(w http.ResponseWriter, r *http.Request, name string, c Controller)
+
+func (h handler) withController(f controllerHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		c, ok := h.controllers[name]
+		if !ok {
+			http.Error(w, "subscription not found", http.StatusNotFound)
+			return
+		}
+
+		f(w, r, name, c)
+	}
+}
+
+func (h handler) list(w http.ResponseWriter, _ *http.Request) {
+	statuses := make([]Status, 0, len(h.controllers))
+
+	for name, c := range h.controllers {
+		statuses = append(statuses, status(name, c))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	writeJSON(w, statuses)
+}
+
+func (handler) get(w http.ResponseWriter, _ *http.Request, name string, c Controller) {
+	writeJSON(w, status(name, c))
+}
+
+func (handler) pause(w http.ResponseWriter, _ *http.Request, name string, c Controller) {
+	c.Pause()
+
+	writeJSON(w, status(name, c))
+}
+
+func (handler) resume(w http.ResponseWriter, _ *http.Request, name string, c Controller) {
+	c.Resume()
+
+	writeJSON(w, status(name, c))
+}
+
+func (handler) setRateLimit(w http.ResponseWriter, r *http.Request, name string, c Controller) {
+	var req RateLimitRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid rate limit request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Burst < 0 {
+		http.Error(w, "invalid rate limit request: negative burst", http.StatusBadRequest)
+		return
+	}
+
+	c.SetRateLimit(req.EventsPerSecond, req.Burst)
+
+	writeJSON(w, status(name, c))
+}
+
+func status(name string, c Controller) Status {
+	eventsPerSecond, burst := c.RateLimit()
+
+	return Status{
+		Name:            name,
+		Paused:          c.Paused(),
+		EventsPerSecond: eventsPerSecond,
+		Burst:           burst,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -22,14 +22,44 @@ type PubSub[T, P any] struct {
 
 	// eventBufferSize is the buffer size of the channel for each subscription.
 	eventBufferSize int
+
+	// maxPending is the number of events kept for a paused subscription.
+	maxPending int
+}
+
+// DefaultMaxPending is the default number of events kept for a paused
+// subscription.
+const DefaultMaxPending = 1024
+
+// Option configures a PubSub.
+type Option func(*options)
+
+type options struct {
+	maxPending int
+}
+
+// WithMaxPending sets the number of events kept for a paused subscription.
+// A paused subscription with more pending events is removed, like a
+// subscription whose channel is full.
+func WithMaxPending(n int) Option {
+	return func(o *options) {
+		o.maxPending = n
+	}
 }
 
 // NewPubSub returns a new instance of PubSub backed
 // by an in memory storage.
-func NewPubSub[T, P any](eventBufferSize int) *PubSub[T, P] {
+func NewPubSub[T, P any](eventBufferSize int, opts ...Option) *PubSub[T, P] {
+	o := options{maxPending: DefaultMaxPending}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &PubSub[T, P]{
 		channelsSubs:    make(map[string]map[*Subscription[T, P]]struct{}),
 		eventBufferSize: eventBufferSize,
+		maxPending:      o.maxPending,
 	}
 }
 
@@ -55,6 +85,21 @@ func (ps *PubSub[T, P]) Publish(event pubsub.Event[T, P], channels ...string) er
 
 		// Iterate over the subscriptions for the current channel.
 		for sub := range subs {
+			// Keep the event for later if the subscription is paused, or
+			// after the events still being delivered since a Resume to
+			// preserve the order. Remove the subscription if too many
+			// events are pending.
+			if sub.paused || sub.flushing {
+				if len(sub.pending) >= ps.maxPending {
+					ps.removeSubscription(sub)
+					continue
+				}
+
+				sub.pending = append(sub.pending, event)
+
+				continue
+			}
+
 			select {
 			// Send the event to the subscriptions go channel.
 			case sub.c <- event:
@@ -85,6 +130,7 @@ func (ps *PubSub[T, P]) Subscribe(channels ...string) (pubsub.Subscription[T, P]
 	sub := &Subscription[T, P]{
 		channels: channels,
 		c:        make(chan pubsub.Event[T, P], ps.eventBufferSize),
+		stop:     make(chan struct{}),
 		pubsub:   ps,
 	}
 
@@ -125,9 +171,15 @@ func (ps *PubSub[T, P]) Unsubscribe(sub *Subscription[T, P]) {
 // removeSubscription closes the subscriptions go channel and
 // removes it from the pubsubs storage.
 func (ps *PubSub[T, P]) removeSubscription(sub *Subscription[T, P]) {
-	// Only close the underlying channel once.
+	// Only close the underlying channel once. While the pending events
+	// are being delivered, the delivering goroutine closes it.
 	sub.once.Do(func() {
-		close(sub.c)
+		sub.removed = true
+		close(sub.stop)
+
+		if !sub.flushing {
+			close(sub.c)
+		}
 	})
 
 	// iterate over the subscriptions channels
@@ -86,3 +86,127 @@ func TestPubSub_UnsubscribeSuccess(t *testing.T) {
 	err = subscription.Close()
 	i.NoErr(err)
 }
+
+func TestPubSub_PauseResume(t *testing.T) {
+	i := is.New(t)
+
+	const (
+		eventBufferSize = 2
+		channelA        = "a"
+	)
+
+	ps := NewPubSub[string, int](eventBufferSize)
+
+	subscription, err := ps.Subscribe(channelA)
+	i.NoErr(err)
+
+	sub := subscription.(*Subscription[string, int])
+
+	sub.Pause()
+	i.True(sub.Paused())
+
+	// Publish more events than the buffer can hold while paused.
+	for n := range 3 {
+		err = ps.Publish(pubsub.Event[string, int]{Type: "test", Payload: n}, channelA)
+		i.NoErr(err)
+	}
+
+	select {
+	case <-sub.C():
+		t.Error("expected no event while paused")
+	default:
+	}
+
+	sub.Resume()
+	i.True(!sub.Paused())
+
+	i.Equal((<-sub.C()).Payload, 0)
+	i.Equal((<-sub.C()).Payload, 1)
+
+	// The event that did not fit on Resume is delivered, in order,
+	// before the next published one.
+	err = ps.Publish(pubsub.Event[string, int]{Type: "test", Payload: 3}, channelA)
+	i.NoErr(err)
+
+	i.Equal((<-sub.C()).Payload, 2)
+	i.Equal((<-sub.C()).Payload, 3)
+}
+
+func TestPubSub_PauseMaxPending(t *testing.T) {
+	i := is.New(t)
+
+	const channelA = "a"
+
+	ps := NewPubSub[string, int](1, WithMaxPending(2))
+
+	subscription, err := ps.Subscribe(channelA)
+	i.NoErr(err)
+
+	sub := subscription.(*Subscription[string, int])
+
+	sub.Pause()
+
+	// The subscription is removed once more events than the limit are
+	// pending.
+	for n := range 3 {
+		err = ps.Publish(pubsub.Event[string, int]{Type: "test", Payload: n}, channelA)
+		i.NoErr(err)
+	}
+
+	_, open := <-sub.C()
+	i.True(!open)
+}
+
+func TestPubSub_ResumeMorePendingThanBuffer(t *testing.T) {
+	i := is.New(t)
+
+	const channelA = "a"
+
+	ps := NewPubSub[string, int](1)
+
+	subscription, err := ps.Subscribe(channelA)
+	i.NoErr(err)
+
+	sub := subscription.(*Subscription[string, int])
+
+	sub.Pause()
+
+	for n := range 5 {
+		err = ps.Publish(pubsub.Event[string, int]{Type: "test", Payload: n}, channelA)
+		i.NoErr(err)
+	}
+
+	sub.Resume()
+
+	// The events published while the pending ones are delivered don't
+	// remove the subscription, they are delivered after them.
+	for n := 5; n < 10; n++ {
+		err = ps.Publish(pubsub.Event[string, int]{Type: "test", Payload: n}, channelA)
+		i.NoErr(err)
+	}
+
+	for n := range 10 {
+		i.Equal((<-sub.C()).Payload, n)
+	}
+
+	// Once delivered, the events are sent straight to the channel again.
+	err = ps.Publish(pubsub.Event[string, int]{Type: "test", Payload: 10}, channelA)
+	i.NoErr(err)
+
+	i.Equal((<-sub.C()).Payload, 10)
+
+	// Closing while events are still pending closes the channel.
+	sub.Pause()
+
+	for n := range 3 {
+		err = ps.Publish(pubsub.Event[string, int]{Type: "test", Payload: n}, channelA)
+		i.NoErr(err)
+	}
+
+	sub.Resume()
+
+	i.NoErr(sub.Close())
+
+	for range sub.C() {
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"github.com/purposeinplay/go-commons/pubsub"
 )
 
-// Ensure type inmem.Subscription implements interface pubsub.Subscription.
-var _ pubsub.Subscription[string, any] = (*Subscription[string, any])(nil)
+// Ensure type inmem.Subscription implements interfaces pubsub.Subscription
+// and pubsub.Pauser.
+var (
+	_ pubsub.Subscription[string, any] = (*Subscription[string, any])(nil)
+	_ pubsub.Pauser                    = (*Subscription[string, any])(nil)
+)
 
 // Subscription represents a stream of events published to the channels
 // of this subscription.
@@ -19,8 +23,22 @@ type Subscription[T, P any] struct {
 	once sync.Once
 	// Channel of events
 	c chan pubsub.Event[T, P]
+	// Closed when the subscription is removed.
+	stop chan struct{}
 
 	pubsub *PubSub[T, P]
+
+	// The fields below are guarded by the mutex of the PubSub.
+
+	// Whether the delivery of events is paused.
+	paused bool
+	// Events published while paused, or while the pending ones are
+	// delivered after a Resume.
+	pending []pubsub.Event[T, P]
+	// Whether a goroutine delivers the pending events.
+	flushing bool
+	// Whether the subscription was removed from the PubSub.
+	removed bool
 }
 
 // Close disconnects the subscription from the service it was created from.
@@ -34,3 +52,79 @@ func (s *Subscription[T, P]) Close() error {
 func (s *Subscription[T, P]) C() <-chan pubsub.Event[T, P] {
 	return s.c
 }
+
+// Pause stops delivering events on the subscription channel. Events
+// published while paused are kept in memory and delivered after Resume,
+// up to the limit set with WithMaxPending: past it, the subscription is
+// removed and its channel closed.
+func (s *Subscription[T, P]) Pause() {
+	s.pubsub.mu.Lock()
+	defer s.pubsub.mu.Unlock()
+
+	s.paused = true
+}
+
+// Resume delivers the events published while paused, in the background,
+// and resumes the delivery of new events after them.
+func (s *Subscription[T, P]) Resume() {
+	s.pubsub.mu.Lock()
+	defer s.pubsub.mu.Unlock()
+
+	s.paused = false
+
+	if len(s.pending) > 0 && !s.flushing && !s.removed {
+		s.flushing = true
+
+		go s.flushPending()
+	}
+}
+
+// Paused reports whether the subscription is paused.
+func (s *Subscription[T, P]) Paused() bool {
+	s.pubsub.mu.Lock()
+	defer s.pubsub.mu.Unlock()
+
+	return s.paused
+}
+
+// flushPending sends the pending events to the subscription channel, in
+// order, until none is left or the subscription is paused again. It waits
+// for the subscriber to make room in the channel, without holding the
+// mutex of the PubSub, so the events published meanwhile are queued after
+// the pending ones.
+// When the subscription is removed meanwhile, it closes the channel.
+func (s *Subscription[T, P]) flushPending() {
+	ps := s.pubsub
+
+	ps.mu.Lock()
+
+	for !s.removed && !s.paused && len(s.pending) > 0 {
+		event := s.pending[0]
+
+		ps.mu.Unlock()
+
+		select {
+		case s.c <- event:
+		case <-s.stop:
+		}
+
+		ps.mu.Lock()
+
+		if !s.removed {
+			s.pending[0] = pubsub.Event[T, P]{}
+			s.pending = s.pending[1:]
+		}
+	}
+
+	s.flushing = false
+
+	if s.removed {
+		close(s.c)
+	}
+
+	if len(s.pending) == 0 {
+		s.pending = nil
+	}
+
+	ps.mu.Unlock()
+}
@@ -49,7 +49,11 @@ func (p Publisher) Publish(event pubsub.Event[string, []byte], channels ...strin
 
 	mes := message.NewMessage(uuid.New().String(), event.Payload)
 
-	mes.Metadata.Set("type", event.Type)
+	for k, v := range event.Headers {
+		mes.Metadata.Set(k, v)
+	}
+
+	mes.Metadata.Set(typeHeader, event.Type)
 
 	if err := p.kafkaPublisher.Publish(
 		channels[0],
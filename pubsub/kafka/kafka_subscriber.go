@@ -2,8 +2,10 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 
 	"github.com/IBM/sarama"
 	"github.com/ThreeDotsLabs/watermill-kafka/v3/pkg/kafka"
@@ -62,12 +64,27 @@ func (s Subscriber) Close() error {
 	return s.kafkaSubscriber.Close()
 }
 
-var _ pubsub.Subscription[string, []byte] = (*Subscription)(nil)
+var (
+	_ pubsub.Subscription[string, []byte] = (*Subscription)(nil)
+	_ pubsub.Shutdowner                   = (*Subscription)(nil)
+)
 
 // Subscription represents a stream of events published to a kafka topic.
+//
+// Every event delivered on the subscription channel carries an Acker backed
+// by the underlying watermill message, the next message is only read after
+// the current one is acked or nacked.
 type Subscription struct {
 	eventCh chan pubsub.Event[string, []byte]
 	closeCh chan struct{}
+	done    chan struct{}
+
+	// abortCh is closed when Shutdown gives up on the in-flight event,
+	// which is then nacked.
+	abortCh chan struct{}
+
+	closeOnce sync.Once
+	abortOnce sync.Once
 }
 
 // newSubscription creates a new subscription.
@@ -75,43 +92,145 @@ type Subscription struct {
 func newSubscription(
 	mesCh <-chan *message.Message,
 ) *Subscription {
-	eventCh := make(chan pubsub.Event[string, []byte])
-	closeCh := make(chan struct{})
+	sub := &Subscription{
+		eventCh: make(chan pubsub.Event[string, []byte]),
+		closeCh: make(chan struct{}),
+		done:    make(chan struct{}),
+		abortCh: make(chan struct{}),
+	}
+
+	go sub.forward(mesCh)
+
+	return sub
+}
+
+// forward sends the messages received from watermill to the event channel
+// until the subscription is closed. It is the only writer of eventCh, so it
+// is also the one that closes it.
+func (s *Subscription) forward(mesCh <-chan *message.Message) {
+	defer close(s.done)
+	defer close(s.eventCh)
+
+	for {
+		// Once the subscription is closing, no new message is read, even
+		// when one is ready as well.
+		if s.closing() {
+			return
+		}
+
+		select {
+		case <-s.closeCh:
+			return
+		case mes, ok := <-mesCh:
+			if !ok {
+				slog.Info("sub closed")
+				return
+			}
+
+			if s.closing() {
+				mes.Nack()
+				return
+			}
 
-	go func() {
-		for {
 			select {
-			case <-closeCh:
+			case s.eventCh <- buildEvent(mes):
+			case <-s.closeCh:
+				mes.Nack()
+				return
+			}
+
+			// Wait for the application to ack or nack the message before
+			// reading the next one, so Shutdown knows what is in flight.
+			select {
+			case <-mes.Acked():
+			case <-mes.Nacked():
+			case <-s.abortCh:
+				mes.Nack()
 				return
-			case mes, ok := <-mesCh:
-				if !ok {
-					slog.Info("sub closed")
-					return
-				}
-
-				eventCh <- pubsub.Event[string, []byte]{
-					Type:    mes.Metadata.Get("type"),
-					Payload: mes.Payload,
-				}
 			}
 		}
-	}()
+	}
+}
 
-	return &Subscription{
-		eventCh: eventCh,
-		closeCh: closeCh,
+// closing reports whether Close or Shutdown was called.
+func (s *Subscription) closing() bool {
+	select {
+	case <-s.closeCh:
+		return true
+	default:
+		return false
 	}
 }
 
 // C returns a receive-only go channel of events published.
-func (s Subscription) C() <-chan pubsub.Event[string, []byte] {
+func (s *Subscription) C() <-chan pubsub.Event[string, []byte] {
 	return s.eventCh
 }
 
-// Close closes the subscription.
-func (s Subscription) Close() error {
-	close(s.eventCh)
-	close(s.closeCh)
+// Close closes the subscription without waiting for the in-flight event,
+// which is nacked.
+func (s *Subscription) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
 
 	return nil
 }
+
+// Shutdown stops reading new messages and waits for the in-flight event to
+// be acked or nacked before closing the event channel. When ctx is done
+// first, the in-flight event is nacked and the returned error wraps
+// ctx.Err().
+func (s *Subscription) Shutdown(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.closeCh) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		s.abortOnce.Do(func() { close(s.abortCh) })
+
+		<-s.done
+
+		return fmt.Errorf("drain in-flight events: %w", ctx.Err())
+	}
+}
+
+// typeHeader is the message metadata key that carries the event type.
+const typeHeader = "type"
+
+func buildEvent(mes *message.Message) pubsub.Event[string, []byte] {
+	var headers map[string]string
+
+	for k, v := range mes.Metadata {
+		if k == typeHeader {
+			continue
+		}
+
+		if headers == nil {
+			headers = make(map[string]string, len(mes.Metadata))
+		}
+
+		headers[k] = v
+	}
+
+	return pubsub.Event[string, []byte]{
+		Type:    mes.Metadata.Get(typeHeader),
+		Payload: mes.Payload,
+		Headers: headers,
+		Acker:   messageAcker{mes},
+	}
+}
+
+// messageAcker adapts a watermill message to the pubsub.Acker interface.
+// Watermill already guarantees that only the first Ack or Nack has an
+// effect.
+type messageAcker struct {
+	mes *message.Message
+}
+
+func (a messageAcker) Ack()  { a.mes.Ack() }
+func (a messageAcker) Nack() { a.mes.Nack() }
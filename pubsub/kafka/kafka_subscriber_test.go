@@ -0,0 +1,142 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/matryer/is"
+)
+
+func TestSubscriptionShutdownWaitsForAck(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	mesCh := make(chan *message.Message, 1)
+	mes := message.NewMessage("1", []byte("payload"))
+	mes.Metadata.Set("type", "test")
+	mesCh <- mes
+
+	sub := newSubscription(mesCh)
+
+	evt := <-sub.C()
+	is.Equal(evt.Type, "test")
+
+	shutdownErr := make(chan error, 1)
+
+	go func() { shutdownErr <- sub.Shutdown(context.Background()) }()
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("shutdown returned before the event was acked: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	evt.Ack()
+
+	is.NoErr(<-shutdownErr)
+
+	select {
+	case <-mes.Acked():
+	default:
+		t.Fatal("expected message to be acked")
+	}
+
+	_, open := <-sub.C()
+	is.True(!open)
+}
+
+func TestSubscriptionShutdownNacksAfterDeadline(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	mesCh := make(chan *message.Message, 1)
+	mes := message.NewMessage("1", []byte("payload"))
+	mesCh <- mes
+
+	sub := newSubscription(mesCh)
+
+	<-sub.C()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := sub.Shutdown(ctx)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+
+	select {
+	case <-mes.Nacked():
+	default:
+		t.Fatal("expected message to be nacked")
+	}
+}
+
+func TestSubscriptionCloseWhileSending(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	mesCh := make(chan *message.Message, 1)
+	mesCh <- message.NewMessage("1", []byte("payload"))
+
+	sub := newSubscription(mesCh)
+
+	// Give the forwarding goroutine time to block on the event channel.
+	time.Sleep(10 * time.Millisecond)
+
+	is.NoErr(sub.Close())
+	is.NoErr(sub.Close())
+
+	_, open := <-sub.C()
+	is.True(!open)
+}
+
+func TestSubscriptionNoDeliveryAfterClose(t *testing.T) {
+	t.Parallel()
+
+	// The next message is ready when the shutdown starts, run it a few
+	// times since select picks between ready cases at random.
+	for range 20 {
+		mesCh := make(chan *message.Message, 1)
+		first := message.NewMessage("1", []byte("payload"))
+		mesCh <- first
+
+		sub := newSubscription(mesCh)
+
+		evt := <-sub.C()
+
+		next := message.NewMessage("2", []byte("payload"))
+		mesCh <- next
+
+		shutdownErr := make(chan error, 1)
+
+		go func() { shutdownErr <- sub.Shutdown(context.Background()) }()
+
+		// Wait for the shutdown to start before acking the first event.
+		time.Sleep(time.Millisecond)
+
+		evt.Ack()
+
+		select {
+		case err := <-shutdownErr:
+			if err != nil {
+				t.Fatalf("shutdown: %v", err)
+			}
+		case evt, open := <-sub.C():
+			if open {
+				t.Fatalf("event delivered after shutdown started: %+v", evt)
+			}
+
+			<-shutdownErr
+		case <-time.After(time.Second):
+			t.Fatal("shutdown did not return")
+		}
+	}
+}
@@ -92,7 +92,10 @@ func TestPubSub(t *testing.T) {
 		defer wg.Done()
 
 		receivedMes := <-sub1.C()
-		is.Equal(receivedMes, mes)
+		is.Equal(receivedMes.Type, mes.Type)
+		is.Equal(receivedMes.Payload, mes.Payload)
+
+		receivedMes.Ack()
 
 		t.Logf("sub1 received the message in %s", time.Since(now))
 	}()
@@ -101,7 +104,10 @@ func TestPubSub(t *testing.T) {
 		defer wg.Done()
 
 		receivedMes := <-sub2.C()
-		is.Equal(receivedMes, mes)
+		is.Equal(receivedMes.Type, mes.Type)
+		is.Equal(receivedMes.Payload, mes.Payload)
+
+		receivedMes.Ack()
 
 		t.Logf("sub2 received the message in %s", time.Since(now))
 	}()
@@ -166,6 +172,7 @@ func TestConsumerGroups(t *testing.T) {
 			select {
 			case mes := <-sub1.C():
 				t.Logf("sub 1: %s", mes.Payload)
+				mes.Ack()
 			case <-ctx.Done():
 				return
 			}
@@ -195,6 +202,7 @@ func TestConsumerGroups(t *testing.T) {
 			select {
 			case mes := <-sub2.C():
 				t.Logf("sub 2: %s", mes.Payload)
+				mes.Ack()
 			case <-ctx.Done():
 				return
 			}
@@ -55,15 +55,28 @@ func (p Publisher) Publish(event pubsub.Event[string, []byte], channels ...strin
 
 	topic := channels[0]
 
+	headers := make([]sarama.RecordHeader, 0, len(event.Headers)+1)
+
+	headers = append(headers, sarama.RecordHeader{
+		Key:   []byte(typeHeader),
+		Value: []byte(event.Type),
+	})
+
+	for k, v := range event.Headers {
+		if k == typeHeader {
+			continue
+		}
+
+		headers = append(headers, sarama.RecordHeader{
+			Key:   []byte(k),
+			Value: []byte(v),
+		})
+	}
+
 	mes := &sarama.ProducerMessage{
-		Topic: topic,
-		Headers: []sarama.RecordHeader{
-			{
-				Key:   []byte("type"),
-				Value: []byte(event.Type),
-			},
-		},
-		Value: sarama.ByteEncoder(event.Payload),
+		Topic:   topic,
+		Headers: headers,
+		Value:   sarama.ByteEncoder(event.Payload),
 	}
 
 	if _, _, err := p.syncProducer.SendMessage(mes); err != nil {
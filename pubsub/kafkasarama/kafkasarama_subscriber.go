@@ -1,12 +1,12 @@
 package kafkasarama
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 
 	"github.com/IBM/sarama"
 	"github.com/dnwe/otelsarama"
@@ -86,6 +86,11 @@ func (s Subscriber) Subscribe(topics ...string) (pubsub.Subscription[string, []b
 
 var _ pubsub.Subscription[string, []byte] = (*Subscription)(nil)
 
+var (
+	_ pubsub.Shutdowner = (*Subscription)(nil)
+	_ pubsub.Pauser     = (*Subscription)(nil)
+)
+
 // Subscription represents a stream of events published to a kafka topic.
 type Subscription struct {
 	eventCh       chan pubsub.Event[string, []byte]
@@ -93,6 +98,20 @@ type Subscription struct {
 	wg            *sync.WaitGroup
 	consumer      sarama.Consumer
 	consumerGroup sarama.ConsumerGroup
+
+	// partitionConsumers are set on the plain consumer path, they are
+	// paused and resumed one by one.
+	partitionConsumers []sarama.PartitionConsumer
+	paused             *atomic.Bool
+
+	// stopping is closed when the subscription should stop handing new
+	// events to the application.
+	stopping     chan struct{}
+	stoppingOnce sync.Once
+	inflight     *inflightTracker
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // newConsumerSubscription creates a new subscription.
@@ -108,6 +127,7 @@ func newConsumerSubscription(
 	}
 
 	eventCh := make(chan pubsub.Event[string, []byte])
+	stopping := make(chan struct{})
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -115,6 +135,8 @@ func newConsumerSubscription(
 
 	wg.Add(len(partitions))
 
+	partitionConsumers := make([]sarama.PartitionConsumer, 0, len(partitions))
+
 	for _, partition := range partitions {
 		partitionConsumer, err := consumer.ConsumePartition(topic, partition, sarama.OffsetNewest)
 		if err != nil {
@@ -123,12 +145,14 @@ func newConsumerSubscription(
 			return nil, fmt.Errorf("consume partition %d for topic %q: %w", partition, topic, err)
 		}
 
+		partitionConsumers = append(partitionConsumers, partitionConsumer)
+
 		go func() {
 			defer wg.Done()
 
 			// consume partition in the background, stop when the context is
 			// cancelled.
-			consumePartition(ctx, logger, partitionConsumer, eventCh)
+			consumePartition(ctx, logger, partitionConsumer, eventCh, stopping)
 		}()
 	}
 
@@ -137,6 +161,11 @@ func newConsumerSubscription(
 		cancelFunc: cancel,
 		wg:         wg,
 		consumer:   consumer,
+		stopping:   stopping,
+		inflight:   newInflightTracker(),
+		paused:     new(atomic.Bool),
+
+		partitionConsumers: partitionConsumers,
 	}, nil
 }
 
@@ -146,51 +175,72 @@ func consumePartition(
 	logger *slog.Logger,
 	partitionConsumer sarama.PartitionConsumer,
 	eventCh chan<- pubsub.Event[string, []byte],
+	stopping <-chan struct{},
 ) {
+	defer func() {
+		if err := partitionConsumer.Close(); err != nil {
+			logger.Error(
+				"close partition consumer error",
+				slog.String("error", err.Error()),
+			)
+		}
+	}()
+
 	for {
+		var evt pubsub.Event[string, []byte]
+
 		select {
 		case m := <-partitionConsumer.Messages():
-			select {
-			case eventCh <- buildEvent(m):
-			case <-ctx.Done():
-				if err := partitionConsumer.Close(); err != nil {
-					logger.Error(
-						"close partition consumer error",
-						slog.String("error", err.Error()),
-					)
-				}
-
-				return
-			}
+			evt = buildEvent(m)
 
 		case err := <-partitionConsumer.Errors():
-			eventCh <- pubsub.Event[string, []byte]{
+			evt = pubsub.Event[string, []byte]{
 				Type:  pubsub.EventTypeError,
 				Error: err,
 			}
 
+		case <-stopping:
+			return
+
 		case <-ctx.Done():
-			if err := partitionConsumer.Close(); err != nil {
-				logger.Error(
-					"close partition consumer error",
-					slog.String("error", err.Error()),
-				)
-			}
+			return
+		}
 
+		select {
+		case eventCh <- evt:
+		case <-stopping:
+			return
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// typeHeader is the message header that carries the event type.
+const typeHeader = "type"
+
 func buildEvent(m *sarama.ConsumerMessage) pubsub.Event[string, []byte] {
-	var typ string
+	var (
+		typ     string
+		headers map[string]string
+	)
 
 	for _, h := range m.Headers {
-		if bytes.Equal(h.Key, []byte("type")) {
+		if h == nil {
+			continue
+		}
+
+		if string(h.Key) == typeHeader {
 			typ = string(h.Value)
 
-			break
+			continue
 		}
+
+		if headers == nil {
+			headers = make(map[string]string, len(m.Headers))
+		}
+
+		headers[string(h.Key)] = string(h.Value)
 	}
 
 	if typ == "" {
@@ -200,6 +250,7 @@ func buildEvent(m *sarama.ConsumerMessage) pubsub.Event[string, []byte] {
 	return pubsub.Event[string, []byte]{
 		Type:    typ,
 		Payload: m.Value,
+		Headers: headers,
 	}
 }
 
@@ -220,35 +271,179 @@ func (m *messageAcker) Ack()  { m.once.Do(func() { m.done <- true }) }
 func (m *messageAcker) Nack() { m.once.Do(func() { m.done <- false }) }
 
 // C returns a receive-only go channel of events published.
-func (s Subscription) C() <-chan pubsub.Event[string, []byte] {
+func (s *Subscription) C() <-chan pubsub.Event[string, []byte] {
 	return s.eventCh
 }
 
-// Close closes the subscription.
-func (s Subscription) Close() error {
-	s.cancelFunc()
-
-	s.wg.Wait()
+// Pause stops fetching messages from the partitions of the subscription.
+// The consumer group session is kept alive, so the partitions are not
+// rebalanced while paused.
+func (s *Subscription) Pause() {
+	s.paused.Store(true)
 
-	close(s.eventCh)
+	if s.consumerGroup != nil {
+		s.consumerGroup.PauseAll()
+	}
 
-	if s.consumer != nil {
-		return s.consumer.Close()
+	for _, pc := range s.partitionConsumers {
+		pc.Pause()
 	}
+}
+
+// Resume resumes fetching messages after a Pause.
+func (s *Subscription) Resume() {
+	s.paused.Store(false)
 
 	if s.consumerGroup != nil {
-		return s.consumerGroup.Close()
+		s.consumerGroup.ResumeAll()
+	}
+
+	for _, pc := range s.partitionConsumers {
+		pc.Resume()
+	}
+}
+
+// Paused reports whether the subscription is paused.
+func (s *Subscription) Paused() bool {
+	return s.paused.Load()
+}
+
+// Close closes the subscription without waiting for in-flight events.
+// Events that were handed to the application but not yet acknowledged are
+// nacked, so their offsets are not committed.
+func (s *Subscription) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.Shutdown(ctx)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown gracefully closes the subscription.
+//
+// It stops fetching new messages, waits for the events already handed to
+// the application to be acked or nacked, commits the marked offsets and
+// closes the underlying consumer. When ctx is done before every in-flight
+// event is acknowledged, the remaining events are nacked and the returned
+// error wraps ctx.Err().
+//
+// Events delivered by a plain partition consumer (no consumer group) do
+// not carry an Acker, so there is nothing to wait for on that path.
+func (s *Subscription) Shutdown(ctx context.Context) error {
+	s.stoppingOnce.Do(func() { close(s.stopping) })
+
+	drainErr := s.inflight.wait(ctx)
+	if drainErr != nil {
+		drainErr = fmt.Errorf("drain in-flight events: %w", drainErr)
+	}
+
+	s.closeOnce.Do(func() {
+		s.cancelFunc()
+
+		s.wg.Wait()
+
+		close(s.eventCh)
+
+		if s.consumer != nil {
+			s.closeErr = s.consumer.Close()
+		}
+
+		if s.consumerGroup != nil {
+			s.closeErr = s.consumerGroup.Close()
+		}
+	})
+
+	return errors.Join(drainErr, s.closeErr)
+}
+
+// inflightTracker keeps track of the events that were handed to the
+// application and are still waiting for an ack or a nack.
+type inflightTracker struct {
+	mu      sync.Mutex
+	ackers  map[*messageAcker]struct{}
+	changed chan struct{}
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{
+		ackers:  make(map[*messageAcker]struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+func (t *inflightTracker) add(a *messageAcker) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ackers[a] = struct{}{}
+}
+
+func (t *inflightTracker) remove(a *messageAcker) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.ackers, a)
+
+	// Wake up the waiters and arm a new signal.
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// wait blocks until there are no in-flight events left. When ctx is done
+// first, every remaining event is nacked and ctx.Err() is returned.
+func (t *inflightTracker) wait(ctx context.Context) error {
+	if !t.drained(ctx.Done()) {
+		t.nackAll()
+
+		return ctx.Err()
 	}
 
 	return nil
 }
 
+// drained blocks until there are no in-flight events left or done is
+// closed, and reports whether the events were drained.
+func (t *inflightTracker) drained(done <-chan struct{}) bool {
+	for {
+		t.mu.Lock()
+		pending := len(t.ackers)
+		changed := t.changed
+		t.mu.Unlock()
+
+		if pending == 0 {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-done:
+			return false
+		}
+	}
+}
+
+func (t *inflightTracker) nackAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for a := range t.ackers {
+		a.Nack()
+	}
+}
+
 func newConsumerGroupSubscription(
 	logger *slog.Logger,
 	consumerGroup sarama.ConsumerGroup,
 	topics []string,
 ) (*Subscription, error) {
 	eventCh := make(chan pubsub.Event[string, []byte])
+	stopping := make(chan struct{})
+	inflight := newInflightTracker()
+	paused := new(atomic.Bool)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -257,9 +452,14 @@ func newConsumerGroupSubscription(
 	wg.Add(1)
 
 	consumer := &consumerGroupHandler{
-		logger:  logger.With(slog.String("component", "kafkasarama.consumer_group_handler")),
-		eventCh: eventCh,
-		ready:   make(chan struct{}),
+		logger:   logger.With(slog.String("component", "kafkasarama.consumer_group_handler")),
+		eventCh:  eventCh,
+		ready:    make(chan struct{}),
+		stopping: stopping,
+		inflight: inflight,
+
+		consumerGroup: consumerGroup,
+		paused:        paused,
 	}
 
 	go func() {
@@ -306,13 +506,23 @@ func newConsumerGroupSubscription(
 		wg:            wg,
 		consumer:      nil,
 		consumerGroup: consumerGroup,
+		stopping:      stopping,
+		inflight:      inflight,
+		paused:        paused,
 	}, nil
 }
 
 type consumerGroupHandler struct {
-	logger  *slog.Logger
-	eventCh chan<- pubsub.Event[string, []byte]
-	ready   chan struct{}
+	logger   *slog.Logger
+	eventCh  chan<- pubsub.Event[string, []byte]
+	ready    chan struct{}
+	stopping <-chan struct{}
+	inflight *inflightTracker
+
+	// consumerGroup and paused are used to keep the partitions claimed
+	// after a rebalance paused.
+	consumerGroup sarama.ConsumerGroup
+	paused        *atomic.Bool
 }
 
 func (h consumerGroupHandler) Setup(_ sarama.ConsumerGroupSession) error {
@@ -321,17 +531,39 @@ func (h consumerGroupHandler) Setup(_ sarama.ConsumerGroupSession) error {
 	return nil
 }
 
-func (consumerGroupHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
+// Cleanup commits the offsets marked during the session, so acknowledged
+// messages are not redelivered after a rebalance or a shutdown.
+func (consumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	session.Commit()
+
+	return nil
+}
 
 func (h consumerGroupHandler) ConsumeClaim(
 	session sarama.ConsumerGroupSession,
 	claim sarama.ConsumerGroupClaim,
 ) error {
+	// PauseAll only affects the partitions claimed at the time it is
+	// called, pause the partitions claimed after a rebalance as well.
+	if h.paused.Load() {
+		h.consumerGroup.Pause(map[string][]int32{
+			claim.Topic(): {claim.Partition()},
+		})
+	}
+
 	// NOTE:
 	// Do not move the code below to a goroutine.
 	// The `ConsumeClaim` itself is called within a goroutine, see:
 	// https://github.com/IBM/sarama/blob/main/consumer_group.go#L27-L29
 	for {
+		// Stop fetching new messages once a shutdown has started, even
+		// when one is ready as well.
+		select {
+		case <-h.stopping:
+			return h.drain(session)
+		default:
+		}
+
 		select {
 		case message, ok := <-claim.Messages():
 			if !ok {
@@ -343,9 +575,15 @@ func (h consumerGroupHandler) ConsumeClaim(
 			evt := buildEvent(message)
 			evt.Acker = acker
 
+			h.inflight.add(acker)
+
 			select {
 			case h.eventCh <- evt:
+			case <-h.stopping:
+				h.inflight.remove(acker)
+				return h.drain(session)
 			case <-session.Context().Done():
+				h.inflight.remove(acker)
 				return nil
 			}
 
@@ -368,9 +606,14 @@ func (h consumerGroupHandler) ConsumeClaim(
 						slog.Int64("offset", message.Offset),
 					)
 				}
+
+				h.inflight.remove(acker)
 			case <-session.Context().Done():
+				h.inflight.remove(acker)
 				return nil
 			}
+		case <-h.stopping:
+			return h.drain(session)
 		// Should return when `session.Context()` is done.
 		// If not, will raise `ErrRebalanceInProgress` or `read tcp <ip>:<port>: i/o timeout` when kafka rebalance. see:
 		// https://github.com/IBM/sarama/issues/1192
@@ -379,3 +622,13 @@ func (h consumerGroupHandler) ConsumeClaim(
 		}
 	}
 }
+
+// drain keeps the claim running until the events in flight on the other
+// claims of the session are acked or nacked. Sarama ends the session as
+// soon as one ConsumeClaim returns, so returning early would drop their
+// acks and leave their offsets uncommitted.
+func (h consumerGroupHandler) drain(session sarama.ConsumerGroupSession) error {
+	h.inflight.drained(session.Context().Done())
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package kafkasarama
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/matryer/is"
+)
+
+const fakeTopic = "topic"
+
+func TestSubscriptionShutdownMultiplePartitions(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	group := newFakeConsumerGroup(0, 1, 2)
+
+	sub, err := newConsumerGroupSubscription(slog.Default(), group, []string{fakeTopic})
+	is.NoErr(err)
+
+	// Partition 2 stays idle while the events of the other two are in
+	// flight.
+	group.send(0, 5)
+	group.send(1, 7)
+
+	first := <-sub.C()
+	second := <-sub.C()
+
+	shutdownErr := make(chan error, 1)
+
+	go func() { shutdownErr <- sub.Shutdown(context.Background()) }()
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("shutdown returned before the events were acked: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first.Ack()
+	second.Ack()
+
+	is.NoErr(<-shutdownErr)
+
+	is.Equal(group.committedOffsets(), map[int32]int64{0: 6, 1: 8})
+}
+
+func TestSubscriptionPauseMultiplePartitions(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	group := newFakeConsumerGroup(0, 1, 2)
+
+	sub, err := newConsumerGroupSubscription(slog.Default(), group, []string{fakeTopic})
+	is.NoErr(err)
+
+	t.Cleanup(func() { is.NoErr(sub.Close()) })
+
+	sub.Pause()
+	is.True(sub.Paused())
+
+	// The partitions claimed after a rebalance are paused as well.
+	group.rebalance()
+
+	deadline := time.Now().Add(time.Second)
+
+	for len(group.pausedPartitions()) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("paused partitions: %v", group.pausedPartitions())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	sub.Resume()
+	is.True(!sub.Paused())
+	is.Equal(len(group.pausedPartitions()), 0)
+}
+
+// fakeConsumerGroup claims every partition of fakeTopic in each session
+// and, like sarama, ends the session as soon as one ConsumeClaim returns.
+type fakeConsumerGroup struct {
+	partitions []int32
+	closed     chan struct{}
+	closeOnce  sync.Once
+
+	mu        sync.Mutex
+	messages  map[int32]chan *sarama.ConsumerMessage
+	cancel    context.CancelFunc
+	marked    map[int32]int64
+	committed map[int32]int64
+	paused    map[int32]bool
+}
+
+func newFakeConsumerGroup(partitions ...int32) *fakeConsumerGroup {
+	return &fakeConsumerGroup{
+		partitions: partitions,
+		closed:     make(chan struct{}),
+		marked:     make(map[int32]int64),
+		committed:  make(map[int32]int64),
+		paused:     make(map[int32]bool),
+	}
+}
+
+func (g *fakeConsumerGroup) Consume(
+	ctx context.Context,
+	_ []string,
+	handler sarama.ConsumerGroupHandler,
+) error {
+	select {
+	case <-g.closed:
+		return sarama.ErrClosedConsumerGroup
+	default:
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	messages := make(map[int32]chan *sarama.ConsumerMessage, len(g.partitions))
+
+	for _, p := range g.partitions {
+		messages[p] = make(chan *sarama.ConsumerMessage, 1)
+	}
+
+	g.mu.Lock()
+	g.messages = messages
+	g.cancel = cancel
+	g.mu.Unlock()
+
+	session := &fakeSession{ctx: ctx, group: g}
+
+	if err := handler.Setup(session); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+
+	for _, p := range g.partitions {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer cancel()
+
+			_ = handler.ConsumeClaim(session, &fakeClaim{partition: p, messages: messages[p]})
+		}()
+	}
+
+	wg.Wait()
+
+	for _, ch := range messages {
+		close(ch)
+	}
+
+	return handler.Cleanup(session)
+}
+
+// send delivers a message on a partition of the current session.
+func (g *fakeConsumerGroup) send(partition int32, offset int64) {
+	g.mu.Lock()
+	ch := g.messages[partition]
+	g.mu.Unlock()
+
+	ch <- &sarama.ConsumerMessage{
+		Topic:     fakeTopic,
+		Partition: partition,
+		Offset:    offset,
+		Value:     []byte("payload"),
+	}
+}
+
+// rebalance ends the current session, Consume is then called again.
+func (g *fakeConsumerGroup) rebalance() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.cancel()
+}
+
+func (g *fakeConsumerGroup) committedOffsets() map[int32]int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	offsets := make(map[int32]int64, len(g.committed))
+
+	for p, o := range g.committed {
+		offsets[p] = o
+	}
+
+	return offsets
+}
+
+func (g *fakeConsumerGroup) pausedPartitions() []int32 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var partitions []int32
+
+	for p, paused := range g.paused {
+		if paused {
+			partitions = append(partitions, p)
+		}
+	}
+
+	return partitions
+}
+
+func (*fakeConsumerGroup) Errors() <-chan error { return nil }
+
+func (g *fakeConsumerGroup) Close() error {
+	g.closeOnce.Do(func() { close(g.closed) })
+
+	return nil
+}
+
+func (g *fakeConsumerGroup) Pause(partitions map[string][]int32) {
+	g.setPaused(partitions[fakeTopic], true)
+}
+
+func (g *fakeConsumerGroup) Resume(partitions map[string][]int32) {
+	g.setPaused(partitions[fakeTopic], false)
+}
+
+func (g *fakeConsumerGroup) PauseAll() { g.setPaused(g.partitions, true) }
+
+func (g *fakeConsumerGroup) ResumeAll() { g.setPaused(g.partitions, false) }
+
+func (g *fakeConsumerGroup) setPaused(partitions []int32, paused bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, p := range partitions {
+		g.paused[p] = paused
+	}
+}
+
+type fakeSession struct {
+	ctx   context.Context
+	group *fakeConsumerGroup
+}
+
+func (s *fakeSession) Claims() map[string][]int32 {
+	return map[string][]int32{fakeTopic: s.group.partitions}
+}
+
+func (*fakeSession) MemberID() string { return "member" }
+
+func (*fakeSession) GenerationID() int32 { return 1 }
+
+func (s *fakeSession) MarkOffset(_ string, partition int32, offset int64, _ string) {
+	s.group.mu.Lock()
+	defer s.group.mu.Unlock()
+
+	s.group.marked[partition] = offset
+}
+
+func (s *fakeSession) Commit() {
+	s.group.mu.Lock()
+	defer s.group.mu.Unlock()
+
+	for p, o := range s.group.marked {
+		s.group.committed[p] = o
+	}
+}
+
+func (*fakeSession) ResetOffset(string, int32, int64, string) {}
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+type fakeClaim struct {
+	partition int32
+	messages  chan *sarama.ConsumerMessage
+}
+
+func (*fakeClaim) Topic() string { return fakeTopic }
+
+func (c *fakeClaim) Partition() int32 { return c.partition }
+
+func (*fakeClaim) InitialOffset() int64 { return 0 }
+
+func (*fakeClaim) HighWaterMarkOffset() int64 { return 0 }
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
@@ -0,0 +1,192 @@
+package liteadapter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/purposeinplay/go-commons/pubsub"
+	"github.com/purposeinplay/go-commons/pubsublite"
+)
+
+var _ pubsub.Publisher[string, []byte] = (*Publisher[[]byte])(nil)
+
+// Publisher implements pubsub.Publisher on top of a pubsublite.Publisher.
+type Publisher[P any] struct {
+	publisher pubsublite.Publisher
+	codec     Codec[P]
+}
+
+// NewPublisher adapts a pubsublite.Publisher to pubsub.Publisher.
+func NewPublisher[P any](publisher pubsublite.Publisher, codec Codec[P]) *Publisher[P] {
+	return &Publisher[P]{
+		publisher: publisher,
+		codec:     codec,
+	}
+}
+
+// Publish encodes the event payload and publishes it on every channel,
+// used as pubsublite topics.
+func (p *Publisher[P]) Publish(event pubsub.Event[string, P], channels ...string) error {
+	if len(channels) == 0 {
+		return pubsub.ErrNoChannel
+	}
+
+	payload, err := p.codec.Encode(event.Payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	for _, channel := range channels {
+		evt := pubsublite.NewEvent(event.Type)
+		evt.Payload = payload
+
+		if err := p.publisher.Publish(channel, evt); err != nil {
+			return fmt.Errorf("publish to %q: %w", channel, err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the wrapped publisher.
+func (p *Publisher[P]) Close() error {
+	return p.publisher.Close()
+}
+
+var _ pubsub.Subscriber[string, []byte] = (*Subscriber[[]byte])(nil)
+
+// Subscriber implements pubsub.Subscriber on top of a pubsublite.Subscriber.
+type Subscriber[P any] struct {
+	subscriber pubsublite.Subscriber
+	codec      Codec[P]
+}
+
+// NewSubscriber adapts a pubsublite.Subscriber to pubsub.Subscriber.
+func NewSubscriber[P any](subscriber pubsublite.Subscriber, codec Codec[P]) *Subscriber[P] {
+	return &Subscriber[P]{
+		subscriber: subscriber,
+		codec:      codec,
+	}
+}
+
+// Subscribe subscribes to exactly one channel, used as pubsublite topic.
+//
+// The events carry an Acker mapped to the pubsublite event. Events whose
+// payload cannot be decoded are delivered as pubsub.EventTypeError events.
+func (s *Subscriber[P]) Subscribe(channels ...string) (pubsub.Subscription[string, P], error) {
+	if len(channels) != 1 {
+		return nil, pubsub.ErrExactlyOneChannelAllowed
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := s.subscriber.Subscribe(ctx, channels[0])
+	if err != nil {
+		cancel()
+
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+
+	sub := &Subscription[P]{
+		codec:   s.codec,
+		eventCh: make(chan pubsub.Event[string, P]),
+		cancel:  cancel,
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go sub.forward(events)
+
+	return sub, nil
+}
+
+var _ pubsub.Subscription[string, []byte] = (*Subscription[[]byte])(nil)
+
+// Subscription is a stream of events received from a pubsublite topic.
+type Subscription[P any] struct {
+	codec Codec[P]
+
+	eventCh chan pubsub.Event[string, P]
+	cancel  context.CancelFunc
+	closing chan struct{}
+	done    chan struct{}
+
+	closeOnce sync.Once
+}
+
+func (s *Subscription[P]) forward(events <-chan *pubsublite.Event) {
+	defer close(s.done)
+	defer close(s.eventCh)
+
+	for {
+		var liteEvt *pubsublite.Event
+
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+
+			liteEvt = evt
+		case <-s.closing:
+			return
+		}
+
+		evt := s.convert(liteEvt)
+
+		select {
+		case s.eventCh <- evt:
+		case <-s.closing:
+			evt.Nack()
+			return
+		}
+	}
+}
+
+func (s *Subscription[P]) convert(liteEvt *pubsublite.Event) pubsub.Event[string, P] {
+	acker := &liteAcker{evt: liteEvt}
+
+	payload, err := s.codec.Decode(liteEvt.Payload)
+	if err != nil {
+		return pubsub.Event[string, P]{
+			Type:  pubsub.EventTypeError,
+			Error: fmt.Errorf("decode %q payload: %w", liteEvt.Type, err),
+			Acker: acker,
+		}
+	}
+
+	return pubsub.Event[string, P]{
+		Type:    liteEvt.Type,
+		Payload: payload,
+		Acker:   acker,
+	}
+}
+
+// C returns a receive-only go channel of events.
+func (s *Subscription[P]) C() <-chan pubsub.Event[string, P] {
+	return s.eventCh
+}
+
+// Close stops the subscription, cancelling the context given to the
+// pubsublite subscriber.
+func (s *Subscription[P]) Close() error {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		close(s.closing)
+	})
+
+	<-s.done
+
+	return nil
+}
+
+// liteAcker maps pubsub.Acker to a pubsublite event.
+type liteAcker struct {
+	evt *pubsublite.Event
+}
+
+func (a *liteAcker) Ack() { a.evt.Ack() }
+
+// Nack nacks the pubsublite event with requeue, so it is redelivered.
+func (a *liteAcker) Nack() { a.evt.Nack(true) }
@@ -0,0 +1,182 @@
+package liteadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/purposeinplay/go-commons/pubsub"
+	"github.com/purposeinplay/go-commons/pubsublite"
+)
+
+var _ pubsublite.Publisher = (*LitePublisher[[]byte])(nil)
+
+// LitePublisher implements pubsublite.Publisher on top of a
+// pubsub.Publisher.
+type LitePublisher[P any] struct {
+	publisher pubsub.Publisher[string, P]
+	codec     Codec[P]
+}
+
+// NewLitePublisher adapts a pubsub.Publisher to pubsublite.Publisher.
+func NewLitePublisher[P any](publisher pubsub.Publisher[string, P], codec Codec[P]) *LitePublisher[P] {
+	return &LitePublisher[P]{
+		publisher: publisher,
+		codec:     codec,
+	}
+}
+
+// Publish decodes the event payload and publishes it to the topic, used as
+// channel.
+func (p *LitePublisher[P]) Publish(topic string, event *pubsublite.Event) error {
+	payload, err := p.codec.Decode(event.Payload)
+	if err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+
+	return p.publisher.Publish(pubsub.Event[string, P]{
+		Type:    event.Type,
+		Payload: payload,
+	}, topic)
+}
+
+// Close closes the wrapped publisher when it has a Close method.
+func (p *LitePublisher[P]) Close() error {
+	if closer, ok := p.publisher.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+var _ pubsublite.Subscriber = (*LiteSubscriber[[]byte])(nil)
+
+// LiteSubscriber implements pubsublite.Subscriber on top of a
+// pubsub.Subscriber.
+type LiteSubscriber[P any] struct {
+	subscriber pubsub.Subscriber[string, P]
+	codec      Codec[P]
+
+	mu            sync.Mutex
+	subscriptions []pubsub.Subscription[string, P]
+}
+
+// NewLiteSubscriber adapts a pubsub.Subscriber to pubsublite.Subscriber.
+func NewLiteSubscriber[P any](subscriber pubsub.Subscriber[string, P], codec Codec[P]) *LiteSubscriber[P] {
+	return &LiteSubscriber[P]{
+		subscriber: subscriber,
+		codec:      codec,
+	}
+}
+
+// Subscribe subscribes to the topic, used as channel, until ctx is done.
+// The underlying subscription is then closed.
+//
+// Acking or nacking the pubsublite event acks or nacks the underlying
+// event, the requeue flag of the nack is not carried over. Events that
+// are not acked when ctx is done are nacked. Error events and events whose
+// payload cannot be encoded are nacked without being delivered.
+func (s *LiteSubscriber[P]) Subscribe(ctx context.Context, topic string) (<-chan *pubsublite.Event, error) {
+	sub, err := s.subscriber.Subscribe(topic)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+
+	s.mu.Lock()
+	s.subscriptions = append(s.subscriptions, sub)
+	s.mu.Unlock()
+
+	out := make(chan *pubsublite.Event)
+
+	go func() {
+		defer close(out)
+		defer s.unsubscribe(sub)
+
+		for {
+			select {
+			case evt, ok := <-sub.C():
+				if !ok {
+					return
+				}
+
+				liteEvt, err := s.convert(evt)
+				if err != nil {
+					evt.Nack()
+					continue
+				}
+
+				select {
+				case out <- liteEvt:
+				case <-ctx.Done():
+					evt.Nack()
+					return
+				}
+
+				go func() {
+					select {
+					case <-liteEvt.Acked():
+						evt.Ack()
+					case <-liteEvt.Nacked():
+						evt.Nack()
+					case <-ctx.Done():
+						evt.Nack()
+					}
+				}()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (s *LiteSubscriber[P]) convert(evt pubsub.Event[string, P]) (*pubsublite.Event, error) {
+	if evt.Error != nil {
+		return nil, evt.Error
+	}
+
+	payload, err := s.codec.Encode(evt.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+
+	liteEvt := pubsublite.NewEvent(evt.Type)
+	liteEvt.Payload = payload
+
+	return liteEvt, nil
+}
+
+// unsubscribe closes sub, unless Close already did.
+func (s *LiteSubscriber[P]) unsubscribe(sub pubsub.Subscription[string, P]) {
+	s.mu.Lock()
+
+	i := slices.Index(s.subscriptions, sub)
+	if i >= 0 {
+		s.subscriptions = slices.Delete(s.subscriptions, i, i+1)
+	}
+
+	s.mu.Unlock()
+
+	if i >= 0 {
+		_ = sub.Close()
+	}
+}
+
+// Close closes every subscription created by Subscribe.
+func (s *LiteSubscriber[P]) Close() error {
+	s.mu.Lock()
+	subscriptions := s.subscriptions
+	s.subscriptions = nil
+	s.mu.Unlock()
+
+	var errs []error
+
+	for _, sub := range subscriptions {
+		errs = append(errs, sub.Close())
+	}
+
+	return errors.Join(errs...)
+}
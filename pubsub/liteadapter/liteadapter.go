@@ -0,0 +1,89 @@
+// Package liteadapter adapts the pubsublite interfaces to the generic
+// pubsub ones and back, so the AMQP stack of pubsublite can be plugged
+// into the generic tooling, and handlers can be shared between both.
+//
+// The payloads are converted with a Codec: pubsublite carries a
+// pubsublite.Payload, the generic interfaces carry any P.
+package liteadapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/purposeinplay/go-commons/pubsublite"
+)
+
+// Codec converts pubsublite payloads to and from P.
+type Codec[P any] interface {
+	Decode(pubsublite.Payload) (P, error)
+	Encode(P) (pubsublite.Payload, error)
+}
+
+var _ Codec[[]byte] = BytesCodec{}
+
+// BytesCodec converts a pubsublite.Payload to and from its JSON encoding,
+// the same bytes pubsublite/amqp puts on the wire.
+type BytesCodec struct{}
+
+// Decode returns the JSON encoding of the payload.
+func (BytesCodec) Decode(payload pubsublite.Payload) ([]byte, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	return b, nil
+}
+
+// Encode parses a JSON object into a payload.
+func (BytesCodec) Encode(b []byte) (pubsublite.Payload, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var payload pubsublite.Payload
+
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return nil, fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	return payload, nil
+}
+
+var _ Codec[struct{}] = JSONCodec[struct{}]{}
+
+// JSONCodec converts a pubsublite.Payload to and from P through JSON, it
+// is meant for struct payloads.
+type JSONCodec[P any] struct{}
+
+// Decode unmarshals the payload into P.
+func (JSONCodec[P]) Decode(payload pubsublite.Payload) (P, error) {
+	var p P
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return p, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	return p, nil
+}
+
+// Encode marshals P into a payload.
+func (JSONCodec[P]) Encode(p P) (pubsublite.Payload, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	var payload pubsublite.Payload
+
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return nil, fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	return payload, nil
+}
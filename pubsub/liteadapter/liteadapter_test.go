@@ -0,0 +1,222 @@
+package liteadapter_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/purposeinplay/go-commons/pubsub"
+	"github.com/purposeinplay/go-commons/pubsub/inmem"
+	"github.com/purposeinplay/go-commons/pubsub/liteadapter"
+	"github.com/purposeinplay/go-commons/pubsublite"
+)
+
+type user struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"`
+}
+
+type flagAcker struct{ acked atomic.Bool }
+
+func (a *flagAcker) Ack()  { a.acked.Store(true) }
+func (a *flagAcker) Nack() {}
+
+// ackingSubscriber attaches the same acker to every event.
+type ackingSubscriber struct {
+	pubsub.Subscriber[string, []byte]
+	acker pubsub.Acker
+}
+
+func (s ackingSubscriber) Subscribe(channels ...string) (pubsub.Subscription[string, []byte], error) {
+	sub, err := s.Subscriber.Subscribe(channels...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan pubsub.Event[string, []byte])
+
+	go func() {
+		defer close(out)
+
+		for evt := range sub.C() {
+			evt.Acker = s.acker
+			out <- evt
+		}
+	}()
+
+	return ackingSubscription{Subscription: sub, c: out}, nil
+}
+
+type ackingSubscription struct {
+	pubsub.Subscription[string, []byte]
+	c chan pubsub.Event[string, []byte]
+}
+
+func (s ackingSubscription) C() <-chan pubsub.Event[string, []byte] { return s.c }
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	var (
+		ps    = inmem.NewPubSub[string, []byte](10)
+		acker = &flagAcker{}
+	)
+
+	// generic -> pubsublite -> generic, over the in-memory backend.
+	var (
+		liteSub = liteadapter.NewLiteSubscriber[[]byte](
+			ackingSubscriber{Subscriber: ps, acker: acker},
+			liteadapter.BytesCodec{},
+		)
+		litePub = liteadapter.NewLitePublisher[[]byte](ps, liteadapter.BytesCodec{})
+
+		sub = liteadapter.NewSubscriber[user](liteSub, liteadapter.JSONCodec[user]{})
+		pub = liteadapter.NewPublisher[user](litePub, liteadapter.JSONCodec[user]{})
+	)
+
+	subscription, err := sub.Subscribe("users")
+	is.NoErr(err)
+
+	t.Cleanup(func() {
+		is.NoErr(subscription.Close())
+		is.NoErr(liteSub.Close())
+	})
+
+	err = pub.Publish(pubsub.Event[string, user]{
+		Type:    "user_created",
+		Payload: user{ID: 1, Email: "john@example.com"},
+	}, "users")
+	is.NoErr(err)
+
+	var evt pubsub.Event[string, user]
+
+	select {
+	case evt = <-subscription.C():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+
+	is.NoErr(evt.Error)
+	is.Equal(evt.Type, "user_created")
+	is.Equal(evt.Payload, user{ID: 1, Email: "john@example.com"})
+
+	evt.Ack()
+
+	deadline := time.Now().Add(time.Second)
+
+	for !acker.acked.Load() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	is.True(acker.acked.Load())
+}
+
+// closeCountingSubscriber counts the subscriptions closed.
+type closeCountingSubscriber struct {
+	pubsub.Subscriber[string, []byte]
+	closed *atomic.Int32
+}
+
+func (s closeCountingSubscriber) Subscribe(channels ...string) (pubsub.Subscription[string, []byte], error) {
+	sub, err := s.Subscriber.Subscribe(channels...)
+	if err != nil {
+		return nil, err
+	}
+
+	return closeCountingSubscription{Subscription: sub, closed: s.closed}, nil
+}
+
+type closeCountingSubscription struct {
+	pubsub.Subscription[string, []byte]
+	closed *atomic.Int32
+}
+
+func (s closeCountingSubscription) Close() error {
+	s.closed.Add(1)
+
+	return s.Subscription.Close()
+}
+
+func TestLiteSubscriberContextDone(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	var (
+		ps     = inmem.NewPubSub[string, []byte](10)
+		closed = new(atomic.Int32)
+	)
+
+	liteSub := liteadapter.NewLiteSubscriber[[]byte](
+		closeCountingSubscriber{Subscriber: ps, closed: closed},
+		liteadapter.BytesCodec{},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := liteSub.Subscribe(ctx, "users")
+	is.NoErr(err)
+
+	cancel()
+
+	select {
+	case _, open := <-events:
+		is.True(!open)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the events channel to close")
+	}
+
+	// The subscription is closed when ctx is done, not again by Close.
+	is.Equal(closed.Load(), int32(1))
+
+	is.NoErr(liteSub.Close())
+	is.Equal(closed.Load(), int32(1))
+}
+
+func TestSubscriberDecodeError(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	ps := inmem.NewPubSub[string, []byte](10)
+
+	liteSub := liteadapter.NewLiteSubscriber[[]byte](ps, liteadapter.BytesCodec{})
+	sub := liteadapter.NewSubscriber[user](liteSub, liteadapter.JSONCodec[user]{})
+
+	subscription, err := sub.Subscribe("users")
+	is.NoErr(err)
+
+	t.Cleanup(func() { is.NoErr(subscription.Close()) })
+
+	err = ps.Publish(pubsub.Event[string, []byte]{
+		Type:    "user_created",
+		Payload: []byte(`{"id": "not a number"}`),
+	}, "users")
+	is.NoErr(err)
+
+	evt := <-subscription.C()
+	is.Equal(evt.Type, pubsub.EventTypeError)
+	is.True(evt.Error != nil)
+}
+
+func TestBytesCodec(t *testing.T) {
+	t.Parallel()
+
+	// nolint: gocritic, revive
+	is := is.New(t)
+
+	payload, err := liteadapter.BytesCodec{}.Encode([]byte(`{"id":1}`))
+	is.NoErr(err)
+	is.Equal(payload, pubsublite.Payload{"id": float64(1)})
+
+	b, err := liteadapter.BytesCodec{}.Decode(payload)
+	is.NoErr(err)
+	is.Equal(string(b), `{"id":1}`)
+}
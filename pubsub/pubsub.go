@@ -8,6 +8,8 @@
 // Its primary job is to wrap implementations of such PubSub systems,
 package pubsub
 
+import "context"
+
 // Publisher is the interface that wraps the basic Publish method.
 type Publisher[T, P any] interface {
 	// Publish publishes an event to specified channels.
@@ -59,6 +61,10 @@ type Event[T, P any] struct {
 	// The actual data from the event.
 	Payload P `json:"payload"`
 
+	// Headers carry metadata about the event, they are mapped to the
+	// message headers of the backends that support them.
+	Headers map[string]string `json:"headers,omitempty"`
+
 	// Carries an error produced by the underlying subscriber.
 	Error error `json:"-"`
 
@@ -81,3 +87,24 @@ func (e Event[T, P]) Nack() {
 		e.Acker.Nack()
 	}
 }
+
+// Shutdowner is implemented by subscriptions that support a graceful drain.
+//
+// Shutdown stops fetching new events, waits for the events already handed
+// to the application to be acknowledged and then closes the subscription.
+// When ctx is done before every in-flight event is acknowledged, the
+// remaining events are nacked and ctx.Err() is returned.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Pauser is implemented by subscriptions whose consumption can be paused
+// without closing them.
+//
+// While paused, the subscription stops fetching new events. Events are
+// neither dropped nor acknowledged, they are delivered after Resume.
+type Pauser interface {
+	Pause()
+	Resume()
+	Paused() bool
+}
@@ -2,7 +2,9 @@ package amqp
 
 import (
 	"crypto/tls"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/streadway/amqp"
 )
 
@@ -55,11 +57,47 @@ type ConsumeConfig struct {
 	noLocal   bool
 	noWait    bool
 	args      amqp.Table
+
+	// ackTimeout is how long an event may stay unacknowledged before the
+	// delivery is nacked. Zero means no timeout.
+	ackTimeout time.Duration
+	// requeueOnAckTimeout tells whether the deliveries nacked because of
+	// the ack timeout are requeued.
+	requeueOnAckTimeout bool
+	// concurrency is the number of events of a subscription that may be
+	// in flight at once.
+	concurrency int
+}
+
+// QosConfig limits the deliveries the broker sends before they are acked.
+// Zero means no limit.
+type QosConfig struct {
+	prefetchCount int
+	prefetchSize  int
+}
+
+// DeadLetterConfig configures where the messages that are nacked without
+// requeue are routed.
+type DeadLetterConfig struct {
+	// exchange receives the dead-lettered messages, with their original
+	// routing key. Dead-lettering is disabled when empty.
+	exchange string
+	// generateQueueName generates the name of the queue bound to the
+	// dead-letter exchange from the name of the topic queue.
+	generateQueueName QueueNameGenerator
+}
+
+// GenerateDeadLetterQueueName generates dead-letter queue names equal to:
+// 	queueName + ".dlq"
+func GenerateDeadLetterQueueName(queueName string) string {
+	return queueName + ".dlq"
 }
 
 type PublishConfig struct {
 	mandatory bool
 	immediate bool
+	// confirm puts the publisher channel in confirm mode.
+	confirm bool
 }
 
 type ConnectionConfig struct {
@@ -75,6 +113,11 @@ type AmqpConfig struct {
 	queueBind  QueueBindConfig
 	consume    ConsumeConfig
 	publish    PublishConfig
+	deadLetter DeadLetterConfig
+	qos        QosConfig
+	// reconnectBackOff returns the backoff used to redial a lost
+	// connection.
+	reconnectBackOff func() backoff.BackOff
 }
 
 type AmqpConfigOption func(config *AmqpConfig)
@@ -97,6 +140,77 @@ func WithQueueNameGenerator(f QueueNameGenerator) AmqpConfigOption {
 	}
 }
 
+// WithAckTimeout nacks the deliveries whose event is not acknowledged
+// within timeout, so a stuck handler does not stall the consumer. requeue
+// tells whether those deliveries are redelivered.
+func WithAckTimeout(timeout time.Duration, requeue bool) AmqpConfigOption {
+	return func(c *AmqpConfig) {
+		c.consume.ackTimeout = timeout
+		c.consume.requeueOnAckTimeout = requeue
+	}
+}
+
+// WithQos limits the unacknowledged deliveries of the subscriber channel
+// to prefetchCount messages and prefetchSize bytes, zero meaning no limit.
+// The limits are shared by all the subscriptions of the subscriber.
+func WithQos(prefetchCount, prefetchSize int) AmqpConfigOption {
+	return func(c *AmqpConfig) {
+		c.qos.prefetchCount = prefetchCount
+		c.qos.prefetchSize = prefetchSize
+	}
+}
+
+// WithConcurrency lets n events of a subscription be in flight at once,
+// the events may then be acked in any order. The prefetch count set with
+// WithQos should be at least n.
+func WithConcurrency(n int) AmqpConfigOption {
+	return func(c *AmqpConfig) {
+		if n < 1 {
+			n = 1
+		}
+
+		c.consume.concurrency = n
+	}
+}
+
+// WithDeadLetterExchange declares a direct exchange named exchange and
+// routes to it the messages nacked without requeue. For every topic queue,
+// a dead-letter queue named by GenerateDeadLetterQueueName is bound to it
+// with the routing key of the topic.
+//
+// The dead-letter exchange is set as a queue argument, RabbitMQ refuses to
+// redeclare an existing queue with different arguments.
+func WithDeadLetterExchange(exchange string) AmqpConfigOption {
+	return func(c *AmqpConfig) {
+		c.deadLetter.exchange = exchange
+	}
+}
+
+// WithDeadLetterQueueNameGenerator overrides the names of the dead-letter
+// queues, f is given the name of the topic queue.
+func WithDeadLetterQueueNameGenerator(f QueueNameGenerator) AmqpConfigOption {
+	return func(c *AmqpConfig) {
+		c.deadLetter.generateQueueName = f
+	}
+}
+
+// WithPublisherConfirms puts the publisher channel in confirm mode, Publish
+// then returns only once the broker acked the message.
+func WithPublisherConfirms() AmqpConfigOption {
+	return func(c *AmqpConfig) {
+		c.publish.confirm = true
+	}
+}
+
+// WithReconnectBackOff sets the backoff used to redial a connection lost
+// by the broker, newBackOff is called for every reconnection. By default
+// the connection is redialed forever, at most every 30 seconds.
+func WithReconnectBackOff(newBackOff func() backoff.BackOff) AmqpConfigOption {
+	return func(c *AmqpConfig) {
+		c.reconnectBackOff = newBackOff
+	}
+}
+
 func NewDefaultConfig() AmqpConfig {
 	return AmqpConfig{
 		queue: QueueConfig{
@@ -130,10 +244,17 @@ func NewDefaultConfig() AmqpConfig {
 			false, // no-local
 			false, // no-wait
 			nil,   // args
+			0,     // ack timeout
+			false, // requeue on ack timeout
+			1,     // concurrency
 		},
 		publish: PublishConfig{
 			mandatory: true,
 			immediate: false,
 		},
+		deadLetter: DeadLetterConfig{
+			exchange:          "",
+			generateQueueName: GenerateDeadLetterQueueName,
+		},
 	}
 }
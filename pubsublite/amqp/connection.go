@@ -1,19 +1,82 @@
 package amqp
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/streadway/amqp"
 )
 
+// ConnectionState is the state of a supervised connection.
+type ConnectionState int
+
+const (
+	// StateConnected means the connection is open and the channels of the
+	// publishers and subscribers are recovered.
+	StateConnected ConnectionState = iota
+	// StateReconnecting means the connection was lost and is being redialed.
+	StateReconnecting
+	// StateClosed means the connection was closed with Close.
+	StateClosed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("ConnectionState(%d)", int(s))
+	}
+}
+
+// StateChange is sent to the channels registered with NotifyState.
+type StateChange struct {
+	State ConnectionState
+	// Err is the reason of the change: the error that closed the
+	// connection, or the error of a failed redial or recovery.
+	Err error
+}
+
+// recoverer is implemented by the publishers and subscribers, they open
+// new channels and redeclare their topology once the connection is back.
+type recoverer interface {
+	recover(conn *amqp.Connection) error
+}
+
+// Connection is a supervised amqp connection.
+//
+// When the broker closes the connection, it is redialed with backoff until
+// it succeeds or Close is called. The publishers and subscribers created
+// on the connection then open new channels, redeclare their exchanges,
+// queues and bindings, and the subscribers resume their consumers. When
+// the recovery fails, the connection is closed and redialed after the
+// next backoff interval.
 type Connection struct {
+	cfg ConnectionConfig
+	// newBackOff returns the backoff of a reconnection.
+	newBackOff func() backoff.BackOff
+
+	// mu guards the fields below.
+	mu             sync.Mutex
 	amqpConnection *amqp.Connection
+	state          ConnectionState
+	listeners      []chan StateChange
+	recoverers     []recoverer
+
+	// closed is closed by Close, it stops the supervision.
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
 func NewConnection(opts ...AmqpConfigOption) (*Connection, error) {
-	var conn *amqp.Connection
-
 	cfg := &AmqpConfig{
 		connection: ConnectionConfig{},
 	}
@@ -22,43 +85,284 @@ func NewConnection(opts ...AmqpConfigOption) (*Connection, error) {
 		opt(cfg)
 	}
 
+	c := &Connection{
+		cfg:        cfg.connection,
+		newBackOff: cfg.reconnectBackOff,
+		state:      StateConnected,
+		closed:     make(chan struct{}),
+	}
+
+	if c.newBackOff == nil {
+		c.newBackOff = newReconnectBackOff
+	}
+
+	var conn *amqp.Connection
+
 	operation := func() error {
 		var err error
 
-		if cfg.connection.amqpConfig != nil {
-			conn, err = amqp.DialConfig(cfg.connection.url, *cfg.connection.amqpConfig)
-		} else if cfg.connection.tlsConfig != nil {
-			conn, err = amqp.DialTLS(cfg.connection.url, cfg.connection.tlsConfig)
-		} else {
-			conn, err = amqp.Dial(cfg.connection.url)
+		conn, err = c.dial()
+
+		return err
+	}
+
+	err := backoff.Retry(operation, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 5))
+	if err != nil {
+		return nil, err
+	}
+
+	c.amqpConnection = conn
+
+	go c.supervise(conn)
+
+	return c, nil
+}
+
+// newReconnectBackOff retries forever, at most every 30 seconds.
+func newReconnectBackOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = 30 * time.Second
+	b.MaxElapsedTime = 0
+
+	return b
+}
+
+func (c *Connection) dial() (*amqp.Connection, error) {
+	var (
+		conn *amqp.Connection
+		err  error
+	)
+
+	if c.cfg.amqpConfig != nil {
+		conn, err = amqp.DialConfig(c.cfg.url, *c.cfg.amqpConfig)
+	} else if c.cfg.tlsConfig != nil {
+		conn, err = amqp.DialTLS(c.cfg.url, c.cfg.tlsConfig)
+	} else {
+		conn, err = amqp.Dial(c.cfg.url)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not establish rabbitmq connection: %w", err)
+	}
+
+	return conn, nil
+}
+
+// supervise waits for the connection to be closed by the broker, then
+// redials it and recovers the publishers and subscribers.
+func (c *Connection) supervise(conn *amqp.Connection) {
+	for {
+		closeCh := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+		var amqpErr *amqp.Error
+
+		select {
+		case <-c.closed:
+			return
+		case amqpErr = <-closeCh:
+		}
+
+		// The connection was closed gracefully, either by Close or by a
+		// caller closing the underlying connection.
+		if amqpErr == nil {
+			return
+		}
+
+		c.setState(StateReconnecting, amqpErr)
+
+		var err error
+
+		conn, err = c.redial()
+		if err != nil {
+			return
+		}
+
+		c.setState(StateConnected, nil)
+	}
+}
+
+// redial dials and recovers the publishers and subscribers with backoff,
+// until it succeeds or the connection is closed. A failed recovery, e.g.
+// a redeclared queue that no longer matches, waits like a failed dial.
+func (c *Connection) redial() (*amqp.Connection, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.closed:
+			cancel()
+		case <-ctx.Done():
 		}
+	}()
 
+	var conn *amqp.Connection
+
+	operation := func() error {
+		var err error
+
+		conn, err = c.dial()
 		if err != nil {
-			return fmt.Errorf("could not establish rabbitmq connection: %w", err)
+			return err
+		}
+
+		if err = c.recover(conn); err != nil {
+			_ = conn.Close()
+
+			return err
 		}
 
 		return nil
 	}
 
-	err := backoff.Retry(operation, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 5))
+	notify := func(err error, _ time.Duration) {
+		c.notify(StateChange{State: StateReconnecting, Err: err})
+	}
+
+	err := backoff.RetryNotify(operation, backoff.WithContext(c.newBackOff(), ctx), notify)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Connection{
-		amqpConnection: conn,
-	}, nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Close was called while dialing.
+	if c.state == StateClosed {
+		_ = conn.Close()
+		return nil, errors.New("connection closed")
+	}
+
+	c.amqpConnection = conn
+
+	return conn, nil
+}
+
+// recover opens new channels for the registered publishers and
+// subscribers.
+func (c *Connection) recover(conn *amqp.Connection) error {
+	c.mu.Lock()
+	recoverers := make([]recoverer, len(c.recoverers))
+	copy(recoverers, c.recoverers)
+	c.mu.Unlock()
+
+	for _, r := range recoverers {
+		if err := r.recover(conn); err != nil {
+			return fmt.Errorf("recover channel: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Connection) register(r recoverer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.recoverers = append(c.recoverers, r)
+}
+
+func (c *Connection) unregister(r recoverer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, registered := range c.recoverers {
+		if registered == r {
+			c.recoverers = append(c.recoverers[:i], c.recoverers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Connection) setState(state ConnectionState, err error) {
+	c.mu.Lock()
+	if c.state == StateClosed {
+		c.mu.Unlock()
+		return
+	}
+	c.state = state
+	c.mu.Unlock()
+
+	c.notify(StateChange{State: state, Err: err})
+}
+
+func (c *Connection) notify(change StateChange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, l := range c.listeners {
+		// Don't let a slow listener block the supervision.
+		select {
+		case l <- change:
+		default:
+		}
+	}
+}
+
+// NotifyState registers a listener for the state changes of the
+// connection and returns it. The changes are dropped when the listener is
+// not ready to receive them, so it should be buffered. The listener is
+// closed when the connection is closed.
+func (c *Connection) NotifyState(listener chan StateChange) chan StateChange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == StateClosed {
+		close(listener)
+		return listener
+	}
+
+	c.listeners = append(c.listeners, listener)
+
+	return listener
 }
 
+// State returns the current state of the connection.
+func (c *Connection) State() ConnectionState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
+
+// Connection returns the underlying amqp connection. It changes after a
+// reconnection.
 func (c *Connection) Connection() *amqp.Connection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.amqpConnection
 }
 
+// Close stops the supervision and closes the underlying connection.
 func (c *Connection) Close() error {
-	err := c.amqpConnection.Close()
-	if err != nil {
-		return err
-	}
+	var err error
 
-	return nil
+	c.closeOnce.Do(func() {
+		close(c.closed)
+
+		c.mu.Lock()
+		c.state = StateClosed
+		conn := c.amqpConnection
+		listeners := c.listeners
+		c.listeners = nil
+		c.mu.Unlock()
+
+		for _, l := range listeners {
+			select {
+			case l <- StateChange{State: StateClosed}:
+			default:
+			}
+
+			close(l)
+		}
+
+		err = conn.Close()
+		if errors.Is(err, amqp.ErrClosed) {
+			// The connection was lost and not redialed yet.
+			err = nil
+		}
+	})
+
+	return err
 }
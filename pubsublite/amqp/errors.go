@@ -0,0 +1,64 @@
+package amqp
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNacked is returned when the broker could not handle a published
+	// message.
+	ErrNacked = errors.New("message nacked by the broker")
+
+	// ErrConfirmAborted is returned when the channel is closed before the
+	// broker confirmed a published message, it may or may not have been
+	// delivered.
+	ErrConfirmAborted = errors.New("channel closed before the broker confirmed the message")
+)
+
+// UnroutableError is returned when a mandatory message could not be
+// routed to any queue.
+type UnroutableError struct {
+	Exchange   string
+	RoutingKey string
+	ReplyCode  uint16
+	ReplyText  string
+}
+
+func (e *UnroutableError) Error() string {
+	return fmt.Sprintf(
+		"message to exchange %q with routing key %q returned: %d %s",
+		e.Exchange,
+		e.RoutingKey,
+		e.ReplyCode,
+		e.ReplyText,
+	)
+}
+
+// BatchError is returned by PublishBatch when some events could not be
+// published. Errs has an entry for every event of the batch, in order,
+// nil for the events that were published.
+type BatchError struct {
+	Errs []error
+}
+
+func (e *BatchError) Error() string {
+	var (
+		failed int
+		first  error
+	)
+
+	for _, err := range e.Errs {
+		if err == nil {
+			continue
+		}
+
+		if first == nil {
+			first = err
+		}
+
+		failed++
+	}
+
+	return fmt.Sprintf("%d of %d events not published, first error: %v", failed, len(e.Errs), first)
+}
@@ -2,6 +2,9 @@ package amqp
 
 import (
 	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
 
 	"github.com/purposeinplay/go-commons/pubsublite"
 	"github.com/streadway/amqp"
@@ -12,49 +15,190 @@ var _ pubsublite.Publisher = &Publisher{}
 type Publisher struct {
 	exchange string
 
-	connection  *Connection
-	amqpChannel *amqp.Channel
+	connection *Connection
+
+	// mu guards channel, which is replaced after a reconnection.
+	mu      sync.Mutex
+	channel *publisherChannel
+
+	// publishMu serializes the publishes in confirm mode, so the
+	// confirmations can be matched with the messages.
+	publishMu sync.Mutex
+	// messageSeq numbers the messages, the number is used to match the
+	// returned messages.
+	messageSeq uint64
 
 	config AmqpConfig
 }
 
-func NewPublisher(conn *Connection, opts ...AmqpConfigOption) (*Publisher, error) {
-	amqpChannel, err := conn.amqpConnection.Channel()
-	if err != nil {
-		return nil, fmt.Errorf("could not start a new broker channel: %w", err)
-	}
+// publisherChannel is a broker channel with its confirm and return
+// listeners.
+type publisherChannel struct {
+	amqpChannel *amqp.Channel
+	confirms    chan amqp.Confirmation
+	returns     chan amqp.Return
+}
 
+func NewPublisher(conn *Connection, opts ...AmqpConfigOption) (*Publisher, error) {
 	cfg := NewDefaultConfig()
 
 	for _, opt := range opts {
 		opt(&cfg)
 	}
 
-	if cfg.exchange.name != "" {
+	p := &Publisher{
+		connection: conn,
+		config:     cfg,
+	}
+
+	channel, err := p.openChannel(conn.Connection())
+	if err != nil {
+		return nil, err
+	}
+
+	p.channel = channel
+
+	conn.register(p)
+
+	return p, nil
+}
+
+// openChannel opens a broker channel and declares the exchange, if any.
+func openChannel(conn *amqp.Connection, exchange ExchangeConfig) (*amqp.Channel, error) {
+	amqpChannel, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("could not start a new broker channel: %w", err)
+	}
+
+	if exchange.name != "" {
 		err = amqpChannel.ExchangeDeclare(
-			cfg.exchange.name,       // Name
-			cfg.exchange.kind,       // Type
-			cfg.exchange.durable,    // Durable
-			cfg.exchange.autoDelete, // Auto-deleted
-			cfg.exchange.internal,   // Internal
-			cfg.exchange.noWait,     // No wait
-			cfg.exchange.args,       // Args
+			exchange.name,       // Name
+			exchange.kind,       // Type
+			exchange.durable,    // Durable
+			exchange.autoDelete, // Auto-deleted
+			exchange.internal,   // Internal
+			exchange.noWait,     // No wait
+			exchange.args,       // Args
 		)
 
 		if err != nil {
+			_ = amqpChannel.Close()
 			return nil, fmt.Errorf("unable to declare exchange: %w", err)
 		}
 	}
 
-	return &Publisher{
-		connection:  conn,
-		amqpChannel: amqpChannel,
-		config:      cfg,
-	}, nil
+	return amqpChannel, nil
 }
 
+// openChannel opens the publisher channel and, in confirm mode, puts it in
+// confirm mode and listens for the confirmations and returned messages.
+func (p *Publisher) openChannel(conn *amqp.Connection) (*publisherChannel, error) {
+	amqpChannel, err := openChannel(conn, p.config.exchange)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := &publisherChannel{amqpChannel: amqpChannel}
+
+	if !p.config.publish.confirm {
+		return channel, nil
+	}
+
+	if err := amqpChannel.Confirm(false); err != nil {
+		_ = amqpChannel.Close()
+		return nil, fmt.Errorf("unable to put channel in confirm mode: %w", err)
+	}
+
+	channel.confirms = amqpChannel.NotifyPublish(make(chan amqp.Confirmation, confirmBuffer))
+	channel.returns = amqpChannel.NotifyReturn(make(chan amqp.Return, confirmBuffer))
+
+	return channel, nil
+}
+
+// confirmBuffer is the size of the confirm and return listeners, the
+// broker connection is blocked while they are full.
+const confirmBuffer = 128
+
+// recover opens a new channel once the connection is redialed.
+func (p *Publisher) recover(conn *amqp.Connection) error {
+	channel, err := p.openChannel(conn)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.channel = channel
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *Publisher) currentChannel() *publisherChannel {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.channel
+}
+
+// Publish sends the event to the exchange. It fails while the connection
+// is being recovered.
+//
+// With WithPublisherConfirms, Publish returns once the broker confirmed the
+// message. A mandatory message that could not be routed to any queue is
+// reported as an *UnroutableError.
 func (p *Publisher) Publish(topic string, event *pubsublite.Event) error {
-	err := p.amqpChannel.Publish(
+	if !p.config.publish.confirm {
+		if err := p.publish(p.currentChannel().amqpChannel, topic, "", event); err != nil {
+			return fmt.Errorf("error enqueuing event: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := p.publishConfirmed(topic, []*pubsublite.Event{event})[0]; err != nil {
+		return fmt.Errorf("error enqueuing event: %w", err)
+	}
+
+	return nil
+}
+
+// PublishBatch sends the events to the exchange. With
+// WithPublisherConfirms, all the events are published before waiting for
+// the confirmations, which is faster than publishing them one by one.
+//
+// When some events could not be published, the returned error is a
+// *BatchError.
+func (p *Publisher) PublishBatch(topic string, events []*pubsublite.Event) error {
+	var errs []error
+
+	if p.config.publish.confirm {
+		errs = p.publishConfirmed(topic, events)
+	} else {
+		amqpChannel := p.currentChannel().amqpChannel
+
+		errs = make([]error, len(events))
+
+		for i, event := range events {
+			errs[i] = p.publish(amqpChannel, topic, "", event)
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return &BatchError{Errs: errs}
+		}
+	}
+
+	return nil
+}
+
+func (p *Publisher) publish(
+	amqpChannel *amqp.Channel,
+	topic string,
+	messageID string,
+	event *pubsublite.Event,
+) error {
+	return amqpChannel.Publish(
 		p.config.exchange.name,     // exchange
 		topic,                      // routing key
 		p.config.publish.mandatory, // mandatory
@@ -62,17 +206,104 @@ func (p *Publisher) Publish(topic string, event *pubsublite.Event) error {
 		amqp.Publishing{
 			ContentType:  "application/json",
 			DeliveryMode: amqp.Persistent,
+			MessageId:    messageID,
 			Body:         []byte(event.Payload.String()),
 		},
 	)
-	if err != nil {
-		return fmt.Errorf("error enqueuing event: %w", err)
+}
+
+// publishConfirmed publishes the events and waits for their confirmations,
+// it returns the error of every event.
+func (p *Publisher) publishConfirmed(topic string, events []*pubsublite.Event) []error {
+	p.publishMu.Lock()
+	defer p.publishMu.Unlock()
+
+	channel := p.currentChannel()
+
+	errs := make([]error, len(events))
+
+	// published holds the indexes of the published events, in the order of
+	// their confirmations.
+	published := make([]int, 0, len(events))
+	byMessageID := make(map[string]int, len(events))
+
+	for i, event := range events {
+		messageID := strconv.FormatUint(atomic.AddUint64(&p.messageSeq, 1), 10)
+
+		if err := p.publish(channel.amqpChannel, topic, messageID, event); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		published = append(published, i)
+		byMessageID[messageID] = i
 	}
 
-	return nil
+	returns := channel.returns
+
+	handleReturn := func(ret amqp.Return) {
+		if i, ok := byMessageID[ret.MessageId]; ok {
+			errs[i] = &UnroutableError{
+				Exchange:   ret.Exchange,
+				RoutingKey: ret.RoutingKey,
+				ReplyCode:  ret.ReplyCode,
+				ReplyText:  ret.ReplyText,
+			}
+		}
+	}
+
+	for len(published) > 0 {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				returns = nil
+				continue
+			}
+
+			handleReturn(ret)
+
+		case confirm, ok := <-channel.confirms:
+			if !ok {
+				for _, i := range published {
+					errs[i] = ErrConfirmAborted
+				}
+
+				return errs
+			}
+
+			// The broker returns an unroutable message before acking it,
+			// make sure the return is handled first.
+			drainReturns(returns, handleReturn)
+
+			i := published[0]
+			published = published[1:]
+
+			if !confirm.Ack && errs[i] == nil {
+				errs[i] = ErrNacked
+			}
+		}
+	}
+
+	return errs
+}
+
+func drainReturns(returns <-chan amqp.Return, handle func(amqp.Return)) {
+	for {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				return
+			}
+
+			handle(ret)
+		default:
+			return
+		}
+	}
 }
 
 func (p *Publisher) Close() error {
+	p.connection.unregister(p)
 	p.connection.Close()
 	// if err := p.amqpChannel.Close(); err != nil {
 	//	return err
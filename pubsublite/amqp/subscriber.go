@@ -3,7 +3,12 @@ package amqp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/purposeinplay/go-commons/pubsublite"
 	"github.com/streadway/amqp"
@@ -17,23 +22,40 @@ type Subscriber struct {
 	name string
 	// name of the exchange
 	topic string
-	// Consumer channel
+	// Consumer channel, replaced after a reconnection.
 	amqpChannel *amqp.Channel
-	// ConsumerTag is a unique tag of the amqp consumer we want to cancel.
-	consumerTag string
-	cfg         AmqpConfig
+	// consumers are the amqp consumers we want to cancel, one for each
+	// Subscribe call, by consumer tag.
+	consumers map[string]*consumer
+	cfg       AmqpConfig
 
+	// mu guards amqpChannel and consumers.
+	mu sync.Mutex
+
+	// closing is closed when the subscriber should stop handing new events
+	// to the application.
 	closing chan struct{}
-}
+	// abort is closed when the shutdown deadline passed, the deliveries
+	// that are still waiting for an ack are requeued.
+	abort chan struct{}
+	// wg tracks the consuming goroutines.
+	wg sync.WaitGroup
 
-func NewSubscription(conn *Connection, opts ...AmqpConfigOption) (*Subscriber, error) {
-	amqpChannel, err := conn.amqpConnection.Channel()
-	if err != nil {
-		return nil, fmt.Errorf("could not start a new broker channel: %w", err)
-	}
+	closeOnce sync.Once
+	closeErr  error
+}
 
-	closing := make(chan struct{})
+// consumer is the state of a Subscribe call needed to resume it after a
+// reconnection.
+type consumer struct {
+	queueName  string
+	routingKey string
+	// resumed receives the deliveries of the consumer recreated on the
+	// new channel.
+	resumed chan (<-chan amqp.Delivery)
+}
 
+func NewSubscription(conn *Connection, opts ...AmqpConfigOption) (*Subscriber, error) {
 	cfg := NewDefaultConfig()
 
 	for _, opt := range opts {
@@ -41,86 +63,242 @@ func NewSubscription(conn *Connection, opts ...AmqpConfigOption) (*Subscriber, e
 		opt(&cfg)
 	}
 
-	if cfg.exchange.name != "" {
-		err = amqpChannel.ExchangeDeclare(
-			cfg.exchange.name,       // Name
-			cfg.exchange.kind,       // Type
-			cfg.exchange.durable,    // Durable
-			cfg.exchange.autoDelete, // Auto-deleted
-			cfg.exchange.internal,   // Internal
-			cfg.exchange.noWait,     // No wait
-			cfg.exchange.args,       // Args
-		)
-
-		if err != nil {
-			return nil, fmt.Errorf("unable to declare exchange: %w", err)
-		}
+	amqpChannel, err := openConsumerChannel(conn.Connection(), cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Subscriber{
+	s := &Subscriber{
 		connection:  conn,
 		amqpChannel: amqpChannel,
+		consumers:   make(map[string]*consumer),
 		cfg:         cfg,
-		closing:     closing,
-	}, nil
+		closing:     make(chan struct{}),
+		abort:       make(chan struct{}),
+	}
+
+	conn.register(s)
+
+	return s, nil
 }
 
+// openConsumerChannel opens a broker channel, declares the exchange and
+// sets the prefetch limits.
+func openConsumerChannel(conn *amqp.Connection, cfg AmqpConfig) (*amqp.Channel, error) {
+	amqpChannel, err := openChannel(conn, cfg.exchange)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.qos.prefetchCount > 0 || cfg.qos.prefetchSize > 0 {
+		err = amqpChannel.Qos(cfg.qos.prefetchCount, cfg.qos.prefetchSize, false)
+		if err != nil {
+			_ = amqpChannel.Close()
+			return nil, fmt.Errorf("unable to set qos: %w", err)
+		}
+	}
+
+	return amqpChannel, nil
+}
+
+// Subscribe declares and binds the queue of the topic and starts consuming
+// it. Every event must be acked or nacked before the next one is delivered,
+// unless WithConcurrency allows more events in flight. Cancelling ctx cancels the consumer and closes the returned channel.
+//
+// When the connection is lost, the consumer is resumed once it is
+// recovered and the returned channel stays open.
 func (s *Subscriber) Subscribe(ctx context.Context, topic string) (<-chan *pubsublite.Event, error) {
 	out := make(chan *pubsublite.Event)
 
-	queueName := s.cfg.queue.generateName(topic)
-	routingKey := s.cfg.queueBind.generateRoutingKey(topic)
-	exchangeName := s.cfg.exchange.name
+	c := &consumer{
+		queueName:  s.cfg.queue.generateName(topic),
+		routingKey: s.cfg.queueBind.generateRoutingKey(topic),
+		resumed:    make(chan (<-chan amqp.Delivery), 1),
+	}
+
+	amqpChannel := s.channel()
 
-	err := s.setupQueue(routingKey, queueName, exchangeName)
+	err := s.setupQueue(amqpChannel, c.routingKey, c.queueName)
 	if err != nil {
 		return nil, err
 	}
 
-	msgs, err := s.createConsumer(queueName)
+	consumerTag := s.cfg.consume.consumer
+	if consumerTag == "" {
+		consumerTag = generateConsumerTag(c.queueName)
+	}
+
+	msgs, err := s.createConsumer(amqpChannel, c.queueName, consumerTag)
 	if err != nil {
 		return nil, err
 	}
 
+	s.mu.Lock()
+	s.consumers[consumerTag] = c
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+
 	go func() {
-	ConsumingLoop:
-		for msg := range msgs {
-			evt := pubsublite.NewEvent(msg.RoutingKey)
-			_ = json.Unmarshal(msg.Body, &evt.Payload)
+		defer s.wg.Done()
+		defer close(out)
+
+		// Every delivery is acked with its own delivery tag, so the
+		// deliveries handled concurrently may be acked in any order.
+		sem := make(chan struct{}, s.cfg.consume.concurrency)
+
+		var handlers sync.WaitGroup
+		defer handlers.Wait()
+
+		for {
+			for msg := range msgs {
+				sem <- struct{}{}
+
+				handlers.Add(1)
+
+				go func(msg amqp.Delivery) {
+					defer handlers.Done()
+					defer func() { <-sem }()
+
+					s.handleDelivery(ctx, msg, out)
+				}(msg)
+			}
+
+			// The deliveries channel is closed when the consumer is
+			// cancelled or when the connection is lost, in which case
+			// the consumer is resumed on the recovered channel.
+			if !s.hasConsumer(consumerTag) {
+				return
+			}
 
 			select {
+			case msgs = <-c.resumed:
 			case <-s.closing:
-				break ConsumingLoop
-			case out <- evt:
-				// log event sent to consumer
+				return
+			case <-ctx.Done():
+				return
+			case <-s.connection.closed:
+				return
 			}
+		}
+	}()
 
-			<-evt.Acked()
-			msg.Ack(false)
+	// Cancel the consumer when ctx is done, the deliveries channel is then
+	// closed and so is out.
+	go func() {
+		select {
+		case <-ctx.Done():
+			if s.removeConsumer(consumerTag) {
+				_ = s.channel().Cancel(consumerTag, false)
+			}
+		case <-s.closing:
 		}
 	}()
 
 	return out, nil
 }
 
-func (s *Subscriber) setupQueue(routingKey, queueName, exchangeName string) error {
-	_, err := s.amqpChannel.QueueDeclare(
+// handleDelivery hands a delivery to the application and acks it once the
+// event is acknowledged. Once the subscriber is closing or ctx is done,
+// deliveries are no longer handed out and are requeued instead.
+func (s *Subscriber) handleDelivery(
+	ctx context.Context,
+	msg amqp.Delivery,
+	out chan<- *pubsublite.Event,
+) {
+	select {
+	case <-s.closing:
+		_ = msg.Nack(false, true)
+		return
+	case <-ctx.Done():
+		_ = msg.Nack(false, true)
+		return
+	default:
+	}
+
+	evt := pubsublite.NewEvent(msg.RoutingKey)
+	_ = json.Unmarshal(msg.Body, &evt.Payload)
+
+	select {
+	case <-s.closing:
+		_ = msg.Nack(false, true)
+		return
+	case <-ctx.Done():
+		_ = msg.Nack(false, true)
+		return
+	case out <- evt:
+		// log event sent to consumer
+	}
+
+	var ackTimeout <-chan time.Time
+
+	if s.cfg.consume.ackTimeout > 0 {
+		timer := time.NewTimer(s.cfg.consume.ackTimeout)
+		defer timer.Stop()
+
+		ackTimeout = timer.C
+	}
+
+	select {
+	case <-evt.Acked():
+		_ = msg.Ack(false)
+	case <-evt.Nacked():
+		_ = msg.Nack(false, evt.Requeue())
+	case <-ackTimeout:
+		settle(msg, evt, s.cfg.consume.requeueOnAckTimeout)
+	case <-s.abort:
+		settle(msg, evt, true)
+	case <-ctx.Done():
+		settle(msg, evt, true)
+	}
+}
+
+// settle nacks the event on behalf of the application, so an ack sent
+// afterwards has no effect. If the application acked it in the meantime,
+// the delivery is acked instead.
+func settle(msg amqp.Delivery, evt *pubsublite.Event, requeue bool) {
+	if !evt.Nack(requeue) {
+		_ = msg.Ack(false)
+		return
+	}
+
+	_ = msg.Nack(false, requeue)
+}
+
+func (s *Subscriber) setupQueue(amqpChannel *amqp.Channel, routingKey, queueName string) error {
+	queueArgs := s.cfg.queue.arguments
+
+	if dlx := s.cfg.deadLetter.exchange; dlx != "" {
+		if err := s.setupDeadLetter(amqpChannel, dlx, routingKey, queueName); err != nil {
+			return err
+		}
+
+		queueArgs = amqp.Table{}
+
+		for k, v := range s.cfg.queue.arguments {
+			queueArgs[k] = v
+		}
+
+		queueArgs["x-dead-letter-exchange"] = dlx
+	}
+
+	_, err := amqpChannel.QueueDeclare(
 		queueName,
 		s.cfg.queue.durable,
 		s.cfg.queue.autoDelete,
 		s.cfg.queue.exclusive,
 		s.cfg.queue.noWait,
-		s.cfg.queue.arguments,
+		queueArgs,
 	)
 	if err != nil {
 		return fmt.Errorf("unable to declare queue: %w", err)
 	}
 
-	if exchangeName != "" {
-		err = s.amqpChannel.QueueBind(
+	if s.cfg.exchange.name != "" {
+		err = amqpChannel.QueueBind(
 			queueName,              // queue name
 			routingKey,             // routing key
-			exchangeName,           // exchange
+			s.cfg.exchange.name,    // exchange
 			s.cfg.queueBind.noWait, // no wait
 			s.cfg.queueBind.args,   // args
 		)
@@ -133,10 +311,50 @@ func (s *Subscriber) setupQueue(routingKey, queueName, exchangeName string) erro
 	return nil
 }
 
-func (s *Subscriber) createConsumer(queueName string) (<-chan amqp.Delivery, error) {
-	msgs, err := s.amqpChannel.Consume(
+// setupDeadLetter declares the dead-letter exchange and the dead-letter
+// queue of the topic queue, bound with the routing key of the topic.
+func (s *Subscriber) setupDeadLetter(amqpChannel *amqp.Channel, dlx, routingKey, queueName string) error {
+	err := amqpChannel.ExchangeDeclare(
+		dlx,      // Name
+		"direct", // Type
+		true,     // Durable
+		false,    // Auto-deleted
+		false,    // Internal
+		false,    // No wait
+		nil,      // Args
+	)
+	if err != nil {
+		return fmt.Errorf("unable to declare dead-letter exchange: %w", err)
+	}
+
+	dlq := s.cfg.deadLetter.generateQueueName(queueName)
+
+	_, err = amqpChannel.QueueDeclare(
+		dlq,
+		true,  // Durable
+		false, // Auto-deleted
+		false, // Exclusive
+		false, // No wait
+		nil,   // Args
+	)
+	if err != nil {
+		return fmt.Errorf("unable to declare dead-letter queue: %w", err)
+	}
+
+	if err := amqpChannel.QueueBind(dlq, routingKey, dlx, false, nil); err != nil {
+		return fmt.Errorf("unable to bind dead-letter queue: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Subscriber) createConsumer(
+	amqpChannel *amqp.Channel,
+	queueName, consumerTag string,
+) (<-chan amqp.Delivery, error) {
+	msgs, err := amqpChannel.Consume(
 		queueName,
-		s.cfg.consume.consumer,
+		consumerTag,
 		s.cfg.consume.autoAck,
 		s.cfg.consume.exclusive,
 		s.cfg.consume.noLocal,
@@ -150,16 +368,154 @@ func (s *Subscriber) createConsumer(queueName string) (<-chan amqp.Delivery, err
 	return msgs, nil
 }
 
-func (s *Subscriber) Close() error {
-	if err := s.amqpChannel.Cancel(s.consumerTag, true); err != nil {
+func (s *Subscriber) hasConsumer(consumerTag string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.consumers[consumerTag]
+
+	return ok
+}
+
+// removeConsumer forgets a consumer and reports whether it was still
+// registered, so each consumer is cancelled only once.
+func (s *Subscriber) removeConsumer(consumerTag string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.consumers[consumerTag]
+	delete(s.consumers, consumerTag)
+
+	return ok
+}
+
+func (s *Subscriber) channel() *amqp.Channel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.amqpChannel
+}
+
+// recover opens a new channel once the connection is redialed, redeclares
+// the queues and bindings and resumes the consumers.
+func (s *Subscriber) recover(conn *amqp.Connection) error {
+	amqpChannel, err := openConsumerChannel(conn, s.cfg)
+	if err != nil {
 		return err
 	}
 
-	if err := s.amqpChannel.Close(); err != nil {
-		return err
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The subscriber was shut down during the recovery.
+	select {
+	case <-s.closing:
+		_ = amqpChannel.Close()
+		return nil
+	default:
 	}
 
-	close(s.closing)
+	s.amqpChannel = amqpChannel
+
+	for consumerTag, c := range s.consumers {
+		if err := s.setupQueue(amqpChannel, c.routingKey, c.queueName); err != nil {
+			return err
+		}
+
+		msgs, err := s.createConsumer(amqpChannel, c.queueName, consumerTag)
+		if err != nil {
+			return err
+		}
+
+		// Drop the deliveries of a failed previous recovery.
+		select {
+		case <-c.resumed:
+		default:
+		}
+
+		c.resumed <- msgs
+	}
 
 	return nil
 }
+
+var consumerTagSeq uint64
+
+// generateConsumerTag returns a consumer tag that is unique within the
+// process, so the consumer can be cancelled on shutdown.
+func generateConsumerTag(queueName string) string {
+	return fmt.Sprintf(
+		"ctag-%s-%d-%d",
+		queueName,
+		os.Getpid(),
+		atomic.AddUint64(&consumerTagSeq, 1),
+	)
+}
+
+// Close closes the subscriber without waiting for in-flight events. The
+// deliveries that were not acknowledged yet are requeued.
+func (s *Subscriber) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown gracefully closes the subscriber.
+//
+// It cancels the consumers so no new deliveries are fetched, waits for the
+// events already handed to the application to be acknowledged and then
+// closes the broker channel. When ctx is done first, the deliveries that
+// are still waiting for an ack are requeued and the returned error wraps
+// ctx.Err().
+func (s *Subscriber) Shutdown(ctx context.Context) error {
+	var drainErr error
+
+	s.closeOnce.Do(func() {
+		close(s.closing)
+
+		s.connection.unregister(s)
+
+		s.mu.Lock()
+		consumers := s.consumers
+		s.consumers = make(map[string]*consumer)
+		amqpChannel := s.amqpChannel
+		s.mu.Unlock()
+
+		for tag := range consumers {
+			if err := amqpChannel.Cancel(tag, false); err != nil && s.closeErr == nil {
+				s.closeErr = fmt.Errorf("cancel consumer %q: %w", tag, err)
+			}
+		}
+
+		drained := make(chan struct{})
+
+		go func() {
+			s.wg.Wait()
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			drainErr = fmt.Errorf("drain in-flight events: %w", ctx.Err())
+
+			close(s.abort)
+			<-drained
+		}
+
+		if err := amqpChannel.Close(); err != nil && s.closeErr == nil {
+			s.closeErr = err
+		}
+	})
+
+	if drainErr != nil {
+		return drainErr
+	}
+
+	return s.closeErr
+}
@@ -69,6 +69,10 @@ type Event struct {
 
 	ackSentType ackType
 
+	// requeue is set by Nack, it tells whether the message should be
+	// redelivered.
+	requeue bool
+
 	// The actual data from the event.
 	Payload Payload `json:"payload"`
 }
@@ -117,6 +121,50 @@ func (e *Event) Acked() <-chan struct{} {
 	return e.ack
 }
 
+// Nack sends message's negative acknowledgement. When requeue is true the
+// message is redelivered, otherwise it is discarded, or dead-lettered when
+// the backend is configured to.
+//
+// Nack is not blocking.
+// Nack is idempotent.
+// False is returned, if Ack is already sent.
+func (e *Event) Nack(requeue bool) bool {
+	e.ackMutex.Lock()
+	defer e.ackMutex.Unlock()
+
+	if e.ackSentType == ack {
+		return false
+	}
+	if e.ackSentType != noAckSent {
+		return true
+	}
+
+	e.ackSentType = nack
+	e.requeue = requeue
+
+	if e.noAck == nil {
+		e.noAck = closedchan
+	} else {
+		close(e.noAck)
+	}
+
+	return true
+}
+
+// Nacked returns a channel that is closed when the event is negatively
+// acknowledged.
+func (e *Event) Nacked() <-chan struct{} {
+	return e.noAck
+}
+
+// Requeue reports whether the event was nacked with requeue.
+func (e *Event) Requeue() bool {
+	e.ackMutex.Lock()
+	defer e.ackMutex.Unlock()
+
+	return e.requeue
+}
+
 func NewEvent(eventType string) *Event {
 	return &Event{
 		Type: eventType,
@@ -0,0 +1,51 @@
+package pubsublite_test
+
+import (
+	"testing"
+
+	"github.com/purposeinplay/go-commons/pubsublite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent_Nack(t *testing.T) {
+	evt := pubsublite.NewEvent("test")
+
+	require.True(t, evt.Nack(false))
+	require.True(t, evt.Nack(true), "nack is idempotent")
+	require.False(t, evt.Requeue(), "only the first nack has an effect")
+	require.False(t, evt.Ack(), "ack after nack is refused")
+
+	select {
+	case <-evt.Nacked():
+	default:
+		t.Fatal("expected nacked channel to be closed")
+	}
+
+	select {
+	case <-evt.Acked():
+		t.Fatal("expected acked channel to be open")
+	default:
+	}
+}
+
+func TestEvent_NackAfterAck(t *testing.T) {
+	evt := pubsublite.NewEvent("test")
+
+	require.True(t, evt.Ack())
+	require.False(t, evt.Nack(true))
+
+	select {
+	case <-evt.Nacked():
+		t.Fatal("expected nacked channel to be open")
+	default:
+	}
+}
+
+func TestEvent_NackWithoutChannels(t *testing.T) {
+	evt := &pubsublite.Event{}
+
+	require.True(t, evt.Nack(true))
+	require.True(t, evt.Requeue())
+
+	<-evt.Nacked()
+}
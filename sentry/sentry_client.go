@@ -42,7 +42,9 @@ func NewClient(
 	return &Client{}, nil
 }
 
-// ReportError reports an error to Sentry.
+// ReportError reports an error to Sentry, with each error of its chain.
+// The errors with a StackTrace() []uintptr method, like the go-commons
+// errors.Error, are reported with the stack they captured.
 func (*Client) ReportError(ctx context.Context, err error) error {
 	hub := hubFromContext(ctx)
 
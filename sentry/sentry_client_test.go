@@ -2,9 +2,13 @@ package sentry_test
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"runtime"
 	"testing"
+	"time"
 
+	sentrygo "github.com/getsentry/sentry-go"
 	"github.com/matryer/is"
 	"github.com/purposeinplay/go-commons/sentry"
 )
@@ -67,3 +71,65 @@ func TestClient(t *testing.T) {
 	err = rep.Close()
 	i.NoErr(err)
 }
+
+// transport records the events sent to Sentry.
+type transport struct {
+	events []*sentrygo.Event
+}
+
+func (*transport) Flush(time.Duration) bool          { return true }
+func (*transport) Configure(sentrygo.ClientOptions)  {}
+func (t *transport) SendEvent(event *sentrygo.Event) { t.events = append(t.events, event) }
+
+// stackError captures its stack like the go-commons errors.Error.
+type stackError struct {
+	stack []uintptr
+}
+
+func newStackError() *stackError {
+	pcs := make([]uintptr, 32)
+
+	n := runtime.Callers(2, pcs)
+
+	return &stackError{stack: pcs[:n]}
+}
+
+func (*stackError) Error() string { return "stack error" }
+
+func (e *stackError) StackTrace() []uintptr { return e.stack }
+
+func TestClient_ReportError_Stack(t *testing.T) {
+	i := is.New(t)
+
+	tr := &transport{}
+
+	client, err := sentrygo.NewClient(sentrygo.ClientOptions{Transport: tr})
+	i.NoErr(err)
+
+	ctx := sentrygo.SetHubOnContext(
+		context.Background(),
+		sentrygo.NewHub(client, sentrygo.NewScope()),
+	)
+
+	stackErr := newStackError()
+	_, _, stackErrLine, _ := runtime.Caller(0)
+	stackErrLine--
+
+	i.NoErr((&sentry.Client{}).ReportError(ctx, fmt.Errorf("wrapped: %w", stackErr)))
+
+	i.Equal(len(tr.events), 1)
+
+	// The exceptions are sorted from the cause to the wrapping error.
+	exceptions := tr.events[0].Exception
+	i.Equal(len(exceptions), 2)
+	i.Equal(exceptions[0].Value, "stack error")
+
+	// The stack captured by the error is reported, not the stack of the
+	// call to ReportError.
+	i.True(exceptions[0].Stacktrace != nil)
+
+	frames := exceptions[0].Stacktrace.Frames
+	i.True(len(frames) > 0)
+	i.Equal(frames[len(frames)-1].Function, "TestClient_ReportError_Stack")
+	i.Equal(frames[len(frames)-1].Lineno, stackErrLine)
+}
@@ -143,9 +143,16 @@ func (e ErrorHandler) WriteErrorResponse(
 			}
 		}
 
+		// The types without an HTTP status are internal errors.
+		status := appError.Type.HTTPStatus()
+		if status < 0 {
+			status = http.StatusInternalServerError
+		}
+
 		response = ProblemDetails{
-			Title:  http.StatusText(appError.Type.HTTPStatus()),
-			Status: appError.Type.HTTPStatusInt32Ptr(),
+			Title: http.StatusText(status),
+			//nolint:gosec // disable G115
+			Status: ptr.To(int32(status)),
 			Code:   appError.Code.StringPtr(),
 			Detail: ptr.To(appError.Message),
 			Errors: &errs,
@@ -1,9 +1,14 @@
 package smartbear_test
 
 import (
+	"context"
 	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/purposeinplay/go-commons/errors"
 	"github.com/purposeinplay/go-commons/smartbear"
 	"github.com/stretchr/testify/require"
 	"k8s.io/utils/ptr"
@@ -36,3 +41,45 @@ func TestA(t *testing.T) {
 
 	t.Log(string(b))
 }
+
+type noopErrorReporter struct{}
+
+func (noopErrorReporter) ReportError(context.Context, error) {}
+
+func TestErrorHandler_ErrorTypes(t *testing.T) {
+	tests := map[errors.ErrorType]int{
+		errors.ErrorTypeNotFound:           http.StatusNotFound,
+		errors.ErrorTypeForbidden:          http.StatusForbidden,
+		errors.ErrorTypeRateLimited:        http.StatusTooManyRequests,
+		errors.ErrorTypePreconditionFailed: http.StatusPreconditionFailed,
+		errors.ErrorTypeUnavailable:        http.StatusServiceUnavailable,
+		errors.ErrorTypeTimeout:            http.StatusGatewayTimeout,
+		errors.ErrorType("unknown"):        http.StatusInternalServerError,
+	}
+
+	handler := smartbear.ErrorHandler{
+		ErrorReporter: noopErrorReporter{},
+		Logger:        slog.New(slog.DiscardHandler),
+	}
+
+	for typ, status := range tests {
+		t.Run(typ.String(), func(t *testing.T) {
+			req := require.New(t)
+
+			rec := httptest.NewRecorder()
+
+			handler.WriteErrorResponse(context.Background(), rec, &errors.Error{
+				Type:    typ,
+				Code:    "code",
+				Message: "message",
+			})
+
+			req.Equal(status, rec.Code)
+
+			var response smartbear.ProblemDetails
+
+			req.NoError(json.NewDecoder(rec.Body).Decode(&response))
+			req.Equal(http.StatusText(status), response.Title)
+		})
+	}
+}
@@ -7,7 +7,7 @@ tool github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen
 require (
 	github.com/getkin/kin-openapi v0.132.0
 	github.com/go-playground/validator/v10 v10.26.0
-	github.com/purposeinplay/go-commons/errors v0.0.14
+	github.com/purposeinplay/go-commons/errors v0.0.16
 	github.com/stretchr/testify v1.10.0
 	k8s.io/utils v0.0.0-20250502105355-0f33e8f1c979
 )
@@ -40,6 +40,7 @@ require (
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
 	golang.org/x/tools v0.33.0 // indirect
+	google.golang.org/grpc v1.71.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
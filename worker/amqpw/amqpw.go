@@ -6,10 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/purposeinplay/go-commons/rand"
 	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/middleware"
 
 	"github.com/streadway/amqp"
 )
@@ -30,17 +34,74 @@ type Options struct {
 
 	// MaxConcurrency restricts the amount of workers in parallel.
 	MaxConcurrency int
+
+	// MaxPriority enables the priorities of the jobs, up to MaxPriority,
+	// with the x-max-priority of the consumed queues. A queue declared
+	// with another max priority must be deleted first. Defaults to 0, no
+	// priority.
+	MaxPriority uint8
+
+	// DelayedExchange is the name of an exchange of the delayed message
+	// exchange plugin, declared by Start, holding the jobs performed with
+	// PerformIn and PerformAt. Defaults to "", the delayed jobs then wait
+	// in per handler delay queues.
+	DelayedExchange string
+
+	// RetryPolicy configures how the failed jobs are retried, it can be
+	// overridden per handler with WithRetryPolicy. Defaults to
+	// worker.DefaultRetryPolicy.
+	RetryPolicy worker.RetryPolicy
+
+	// JobTimeout cancels the context of a job that runs longer, it can be
+	// overridden per handler with WithTimeout. Zero means no timeout.
+	JobTimeout time.Duration
+
+	// Middleware wraps every handler, outside the middlewares given with
+	// WithMiddleware. The first middleware is the outermost one. The
+	// handlers are always wrapped with middleware.Recover first, so a
+	// panicking job fails and is retried instead of crashing the worker.
+	Middleware []worker.Middleware
+
+	// ShutdownTimeout bounds how long Stop waits for the running jobs.
+	// Defaults to 30s.
+	ShutdownTimeout time.Duration
+
+	// UniqueStore reserves the keys of the unique jobs, see
+	// worker.Job.UniqueKey. It must be shared by the processes performing
+	// jobs.
+	UniqueStore worker.UniqueStore
+
+	// StatusStore records the state of the jobs, see the status package.
+	// It must be shared by the processes performing jobs. Defaults to nil,
+	// the state is not recorded.
+	StatusStore worker.StatusStore
 }
 
 // ErrInvalidConnection is returned when the Connection opt is not defined.
 var ErrInvalidConnection = errors.New("invalid connection")
 
+// ErrNotDrained is returned by StopContext when jobs were still running at
+// its deadline.
+var ErrNotDrained = errors.New("worker not drained")
+
+// ErrStopped is returned when a handler is registered after Stop.
+var ErrStopped = errors.New("worker stopped")
+
+// ErrUnknownHandler is the error of the jobs of a named queue whose handler
+// is not registered, they are retried.
+var ErrUnknownHandler = errors.New("unknown handler")
+
+type handler struct {
+	h   worker.ContextHandler
+	cfg handlerConfig
+}
+
 // Ensures Adapter implements the Worker interface.
-var _ worker.Worker = &Adapter{}
+var _ worker.ContextWorker = &Adapter{}
 
 // New creates a new AMQP adapter.
 func New(opts Options) (*Adapter, error) {
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
 
 	if opts.Name == "" {
 		opts.Name = "win"
@@ -54,42 +115,89 @@ func New(opts Options) (*Adapter, error) {
 		opts.Logger = slog.Default()
 	}
 
+	if opts.RetryPolicy.MaxAttempts == 0 {
+		opts.RetryPolicy = worker.DefaultRetryPolicy()
+	}
+
+	if opts.ShutdownTimeout == 0 {
+		opts.ShutdownTimeout = 30 * time.Second
+	}
+
 	return &Adapter{
-		Connection:     opts.Connection,
-		Logger:         opts.Logger,
-		consumerName:   opts.Name,
-		maxConcurrency: opts.MaxConcurrency,
-		ctx:            ctx,
+		Connection:      opts.Connection,
+		Logger:          opts.Logger,
+		consumerName:    opts.Name,
+		maxConcurrency:  opts.MaxConcurrency,
+		delayedExchange: opts.DelayedExchange,
+		maxPriority:     opts.MaxPriority,
+		retryPolicy:     opts.RetryPolicy,
+		jobTimeout:      opts.JobTimeout,
+		middleware:      opts.Middleware,
+		uniqueStore:     opts.UniqueStore,
+		statusStore:     opts.StatusStore,
+		shutdownTimeout: opts.ShutdownTimeout,
+		ctx:             ctx,
+		cancel:          cancel,
+		handlers:        make(map[string]handler),
+		namedQueues:     make(map[string]bool),
+		stopping:        make(chan struct{}),
 	}, nil
 }
 
 // Adapter implements the buffalo.Worker interface.
 type Adapter struct {
-	Connection     *amqp.Connection
-	Channel        *amqp.Channel
-	Logger         *slog.Logger
-	consumerName   string
-	exchange       string
-	ctx            context.Context
-	maxConcurrency int
+	Connection      *amqp.Connection
+	Channel         *amqp.Channel
+	Logger          *slog.Logger
+	consumerName    string
+	exchange        string
+	ctx             context.Context
+	maxConcurrency  int
+	delayedExchange string
+	maxPriority     uint8
+	retryPolicy     worker.RetryPolicy
+	jobTimeout      time.Duration
+	middleware      []worker.Middleware
+	uniqueStore     worker.UniqueStore
+	statusStore     worker.StatusStore
+	shutdownTimeout time.Duration
+
+	// cancel cancels ctx, the context of the running jobs.
+	cancel context.CancelFunc
+
+	// mu guards the fields below and the closing of stopping.
+	mu           sync.Mutex
+	consumerTags []string
+	handlers     map[string]handler
+	// namedQueues are the named queues consumed.
+	namedQueues map[string]bool
+
+	// stopping is closed when the adapter starts stopping.
+	stopping chan struct{}
+	stopOnce sync.Once
+	stopErr  error
+	drained  atomic.Bool
+	// jobs tracks the running jobs.
+	jobs sync.WaitGroup
 }
 
-// Start connects to the broker.
+// Start connects to the broker. When ctx is done, the adapter stops
+// gracefully with Stop: the context of the running jobs is only cancelled
+// when StopContext gives up on them.
 func (q *Adapter) Start(ctx context.Context) error {
-	q.ctx = ctx
+	// Ensure Connection is defined
+	if q.Connection == nil {
+		return ErrInvalidConnection
+	}
 
 	go func() {
 		select {
 		case <-ctx.Done():
 			_ = q.Stop()
+		case <-q.stopping:
 		}
 	}()
 
-	// Ensure Connection is defined
-	if q.Connection == nil {
-		return ErrInvalidConnection
-	}
-
 	// Start new broker channel
 	c, err := q.Connection.Channel()
 	if err != nil {
@@ -99,6 +207,12 @@ func (q *Adapter) Start(ctx context.Context) error {
 
 	q.Channel = c
 
+	// Prefetch as many jobs as run in parallel, so the broker keeps the
+	// others and delivers them by priority.
+	if err := c.Qos(q.maxConcurrency, 0, false); err != nil {
+		return fmt.Errorf("unable to set the prefetch count: %w", err)
+	}
+
 	// Declare exchange
 	if q.exchange != "" {
 		err = c.ExchangeDeclare(
@@ -116,42 +230,179 @@ func (q *Adapter) Start(ctx context.Context) error {
 		}
 	}
 
+	// Declare delayed message exchange
+	if q.delayedExchange != "" {
+		err = c.ExchangeDeclare(
+			q.delayedExchange,   // Name
+			"x-delayed-message", // Type
+			true,                // Durable
+			false,               // Auto-deleted
+			false,               // Internal
+			false,               // No wait
+			amqp.Table{"x-delayed-type": "direct"},
+		)
+
+		if err != nil {
+			return fmt.Errorf("unable to declare delayed exchange: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// Stop closes the connection to the broker.
+// Stop stops the adapter like StopContext, waiting for the running jobs
+// up to the ShutdownTimeout.
 func (q *Adapter) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), q.shutdownTimeout)
+	defer cancel()
+
+	return q.StopContext(ctx)
+}
+
+// StopContext stops consuming, waits for the running jobs to return until
+// ctx is done, then closes the connection to the broker.
+//
+// The jobs delivered but not started yet are requeued. The running jobs
+// are acked or retried when they return. When ctx is done first, their
+// context is cancelled and ErrNotDrained is returned: the jobs failing
+// because of it are requeued, and so are the ones still running when the
+// channel is closed, by the broker. They run again without counting an
+// attempt.
+//
+// Only the first call stops the adapter, the next ones return its result.
+func (q *Adapter) StopContext(ctx context.Context) error {
+	q.stopOnce.Do(func() {
+		q.stopErr = q.stop(ctx)
+	})
+
+	return q.stopErr
+}
+
+func (q *Adapter) stop(ctx context.Context) error {
 	q.Logger.Info("stopping AMQP worker")
 
+	// No job starts once stopping is closed.
+	q.mu.Lock()
+	close(q.stopping)
+	consumerTags := q.consumerTags
+	q.mu.Unlock()
+
 	if q.Channel == nil {
+		q.drained.Store(true)
 		return nil
 	}
 
+	// Stop the deliveries, the ones already received are requeued.
+	for _, tag := range consumerTags {
+		if err := q.Channel.Cancel(tag, false); err != nil {
+			q.Logger.Error("unable to cancel consumer", slog.String("consumer", tag), slog.Any("error", err))
+		}
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		q.jobs.Wait()
+		close(done)
+	}()
+
+	var stopErr error
+
+	select {
+	case <-done:
+		q.drained.Store(true)
+	case <-ctx.Done():
+		q.Logger.Error("jobs still running, stopping anyway", slog.Any("error", ctx.Err()))
+
+		q.cancel()
+
+		stopErr = fmt.Errorf("%w: %w", ErrNotDrained, ctx.Err())
+	}
+
 	if err := q.Channel.Close(); err != nil {
-		return err
+		return errors.Join(stopErr, err)
+	}
+
+	if err := q.Connection.Close(); err != nil {
+		return errors.Join(stopErr, err)
 	}
 
-	return q.Connection.Close()
+	return stopErr
+}
+
+// Drained reports whether the adapter stopped after all its running jobs
+// returned, so none of them will run again.
+func (q *Adapter) Drained() bool {
+	return q.drained.Load()
 }
 
 // Perform enqueues a new job.
-func (q Adapter) Perform(job worker.Job) error {
+func (q *Adapter) Perform(job worker.Job) error {
+	return q.PerformContext(context.Background(), job)
+}
+
+// PerformContext enqueues a new job. The trace context and request id of
+// ctx are sent in the message headers and restored in the context of the
+// handler.
+//
+// The job is routed to its Queue, or else to the queue of its handler. Its
+// Priority is honored up to the MaxPriority of the adapter.
+//
+// A unique job performed while an identical one is pending is rejected
+// with worker.ErrDuplicateJob or coalesced, see worker.Job.UniqueKey.
+func (q *Adapter) PerformContext(ctx context.Context, job worker.Job) error {
+	return q.perform(ctx, job, 0)
+}
+
+// perform enqueues a job due in delay, right away when it is not positive.
+func (q *Adapter) perform(ctx context.Context, job worker.Job, delay time.Duration) error {
+	if job.ID == "" {
+		job.ID = worker.NewJobID()
+	}
+
 	q.Logger.Info("enqueuing job", slog.Any("job", job))
 
-	err := q.Channel.Publish(
-		q.exchange,  // exchange
-		job.Handler, // routing key
-		true,        // mandatory
-		false,       // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp.Persistent,
-			Body:         []byte(job.Args.String()),
-		},
-	)
+	enqueue, err := worker.AcquireUnique(ctx, q.uniqueStore, job)
+	if err != nil || !enqueue {
+		return err
+	}
+
+	// Record the job before it can run.
+	if err := worker.TrackEnqueued(ctx, q.statusStore, job); err != nil {
+		q.Logger.Error("unable to record job", slog.Any("job", job), slog.Any("error", err))
+	}
+
+	headers := injectHeaders(ctx)
+	headers[handlerHeader] = job.Handler
+	headers[jobIDHeader] = job.ID
+
+	if job.UniqueKey != "" {
+		headers[uniqueKeyHeader] = job.UniqueKey
+	}
+
+	// The jobs with a Queue are routed to the named queue.
+	queue := job.Handler
+	if job.Queue != "" {
+		queue = job.Queue
+	}
+
+	msg := publishing(headers, []byte(job.Args.String()), job.Priority)
+
+	if delay > 0 {
+		err = q.publishDelayed(queue, delay, msg)
+	} else {
+		err = q.publishToExchange(q.exchange, queue, msg)
+	}
+
 	if err != nil {
 		q.Logger.Error("error enqueuing job", slog.Any("job", job))
 
+		_ = worker.ReleaseUnique(ctx, q.uniqueStore, job)
+
+		// The job won't run and the caller gets the error, so it is not
+		// left for status.Inspector.Retry to perform again.
+		_ = worker.Untrack(ctx, q.statusStore, job.ID)
+
 		return fmt.Errorf("error enqueuing job: %w", err)
 	}
 
@@ -160,10 +411,92 @@ func (q Adapter) Perform(job worker.Job) error {
 
 // Register consumes a task, using the declared worker.Handler.
 func (q *Adapter) Register(name string, h worker.Handler) error {
+	return q.RegisterHandler(name, worker.AdaptHandler(h))
+}
+
+// RegisterContext consumes a task, using the declared worker.ContextHandler.
+func (q *Adapter) RegisterContext(name string, h worker.ContextHandler) error {
+	return q.RegisterHandler(name, h)
+}
+
+// RegisterHandler consumes a task, using the declared worker.ContextHandler
+// configured with opts.
+//
+// A job whose handler fails is retried with the backoff of the retry
+// policy, through queues whose messages expire after the backoff. Once its
+// attempts are exhausted, the job is moved to the "<name>_dead" queue.
+//
+// The jobs performed with a Queue are consumed from that queue once a
+// handler is registered on it with WithQueue.
+func (q *Adapter) RegisterHandler(name string, h worker.ContextHandler, opts ...HandlerOption) error {
 	q.Logger.Info("register job", slog.String("job", name))
 
+	cfg := handlerConfig{
+		retryPolicy: q.retryPolicy,
+		timeout:     q.jobTimeout,
+		middleware:  slices.Clone(q.middleware),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	select {
+	case <-q.stopping:
+		return ErrStopped
+	default:
+	}
+
+	q.mu.Lock()
+	q.handlers[name] = handler{h: worker.Chain(h, slices.Insert(cfg.middleware, 0, middleware.Recover())...), cfg: cfg}
+	q.mu.Unlock()
+
+	if err := q.consume(name, name); err != nil {
+		return err
+	}
+
+	for _, queue := range cfg.queues {
+		q.mu.Lock()
+		consumed := q.namedQueues[queue]
+		q.namedQueues[queue] = true
+		q.mu.Unlock()
+
+		if consumed {
+			continue
+		}
+
+		if err := q.consume(queue, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// consume declares queue, with its dead queue, and runs its jobs. The jobs
+// of a handler queue run handlerName, the ones of a named queue run the
+// handler of their header.
+func (q *Adapter) consume(queue, handlerName string) error {
+	args := amqp.Table{}
+
+	if q.maxPriority > 0 {
+		args["x-max-priority"] = q.maxPriority
+	}
+
 	_, err := q.Channel.QueueDeclare(
-		name,
+		queue,
+		true,
+		false,
+		false,
+		false,
+		args,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create queue: %w", err)
+	}
+
+	_, err = q.Channel.QueueDeclare(
+		deadQueueName(queue),
 		true,
 		false,
 		false,
@@ -174,9 +507,18 @@ func (q *Adapter) Register(name string, h worker.Handler) error {
 		return fmt.Errorf("unable to create queue: %w", err)
 	}
 
+	if q.delayedExchange != "" {
+		err := q.Channel.QueueBind(queue, queue, q.delayedExchange, false, nil)
+		if err != nil {
+			return fmt.Errorf("unable to bind queue to delayed exchange: %w", err)
+		}
+	}
+
+	consumerTag := fmt.Sprintf("%s_%s_%s", q.consumerName, queue, rand.String(20))
+
 	msgs, err := q.Channel.Consume(
-		name,
-		fmt.Sprintf("%s_%s_%s", q.consumerName, name, rand.String(20)),
+		queue,
+		consumerTag,
 		false, // auto-ack
 		false, // exclusive
 		false, // no-local
@@ -187,84 +529,181 @@ func (q *Adapter) Register(name string, h worker.Handler) error {
 		return fmt.Errorf("could not consume queue: %w", err)
 	}
 
+	q.mu.Lock()
+	q.consumerTags = append(q.consumerTags, consumerTag)
+	q.mu.Unlock()
+
 	// Process jobs with maxConcurrency workers
 	sem := make(chan bool, q.maxConcurrency)
 	go func() {
 		for d := range msgs {
-			sem <- true
-			q.Logger.Info("received job", slog.String("job", name), slog.Any("body", d.Body))
-
-			args := worker.Args{}
-
-			err := json.Unmarshal(d.Body, &args)
-			if err != nil {
-				q.Logger.Info("unable to retrieve job", slog.String("job", name), slog.Any("error", err))
+			// Don't start the jobs delivered while stopping.
+			select {
+			case sem <- true:
+			case <-q.stopping:
+				q.requeue(queue, d)
 				continue
 			}
 
-			if err := h(args); err != nil {
-				q.Logger.Info("unable to process job", slog.String("job", name), slog.Any("error", err))
+			if !q.startJob() {
+				<-sem
+
+				q.requeue(queue, d)
+
 				continue
 			}
 
-			if err := d.Ack(false); err != nil {
-				q.Logger.Info("unable to ack job", slog.String("job", name), slog.Any("error", err))
-			}
-		}
+			go func(d amqp.Delivery) {
+				defer q.jobs.Done()
+				defer func() { <-sem }()
 
-		for i := 0; i < cap(sem); i++ {
-			sem <- true
+				q.handle(queue, handlerName, d)
+			}(d)
 		}
 	}()
 
 	return nil
 }
 
-// PerformIn performs a job delayed by the given duration.
-func (q Adapter) PerformIn(job worker.Job, t time.Duration) error {
-	q.Logger.Info("enqueuing job", slog.Any("job", job))
-	d := int64(t / time.Second)
-
-	// Trick broker using x-dead-letter feature:
-	// the message will be pushed in a temp queue with the given duration as TTL.
-	// When the TTL expires, the message is forwarded to the original queue.
-	dq, err := q.Channel.QueueDeclare(
-		fmt.Sprintf("%s_delayed_%d", job.Handler, d),
-		true, // Save on disk
-		true, // Auto-deletion
-		false,
-		true,
-		amqp.Table{
-			"x-message-ttl":             d,
-			"x-dead-letter-exchange":    "",
-			"x-dead-letter-routing-key": job.Handler,
-		},
-	)
-	if err != nil {
-		q.Logger.Info("error creating delayed temp queue for job", slog.String("job", job.Handler), slog.Any("error", err))
-		return err
+// handle runs the handler of a job delivered from queue, then acks the
+// delivery or schedules a retry. When handlerName is empty, the handler is
+// the one of the job headers.
+func (q *Adapter) handle(queue, handlerName string, d amqp.Delivery) {
+	// The job waited in a delay queue and is not due yet.
+	if q.delay(queue, d) {
+		return
+	}
+
+	name := handlerName
+	if name == "" {
+		name, _ = d.Headers[handlerHeader].(string)
 	}
 
-	err = q.Channel.Publish(
-		q.exchange, // exchange
-		dq.Name,    // publish to temp delayed queue
-		true,       // mandatory
-		false,      // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp.Persistent,
-			Body:         []byte(job.Args.String()),
+	q.Logger.Info("received job", slog.String("job", name), slog.Any("body", d.Body))
+
+	q.mu.Lock()
+	h, registered := q.handlers[name]
+	q.mu.Unlock()
+
+	if !registered {
+		q.Logger.Info("unknown job handler", slog.String("job", name), slog.String("queue", queue))
+
+		// Another process may know the handler.
+		q.retry(queue, name, handlerConfig{retryPolicy: q.retryPolicy}, d, ErrUnknownHandler)
+
+		return
+	}
+
+	// The job is no longer pending, an identical one can be performed.
+	if uniqueKey, ok := d.Headers[uniqueKeyHeader].(string); ok && attempts(d) == 0 {
+		job := worker.Job{Handler: name, UniqueKey: uniqueKey}
+
+		if err := worker.ReleaseUnique(q.ctx, q.uniqueStore, job); err != nil {
+			q.Logger.Error("unable to release unique job", slog.String("job", name), slog.Any("error", err))
+		}
+	}
+
+	args := worker.Args{}
+
+	if err := json.Unmarshal(d.Body, &args); err != nil {
+		q.Logger.Info("unable to retrieve job", slog.String("job", name), slog.Any("error", err))
+
+		// The job can't succeed on retry.
+		q.retry(queue, name, handlerConfig{retryPolicy: worker.NoRetry()}, d, err)
+
+		return
+	}
+
+	attempt := attempts(d) + 1
+
+	q.track(d, worker.StatusRunning, attempt, nil)
+
+	ctx := worker.WithJobInfo(
+		extractContext(q.ctx, d.Headers),
+		worker.JobInfo{
+			Handler: name,
+			Attempt: attempt,
+			Final:   h.cfg.retryPolicy.Exhausted(attempt),
 		},
 	)
 
-	if err != nil {
-		q.Logger.Info("error enqueuing job", slog.String("job", job.Handler), slog.Any("error", err))
-		return err
+	if h.cfg.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.timeout)
+		defer cancel()
 	}
-	return nil
+
+	if err := h.h(ctx, args); err != nil {
+		// The job was interrupted by the worker stopping, it runs again
+		// without counting an attempt.
+		if q.ctx.Err() != nil {
+			q.Logger.Info("job interrupted", slog.String("job", name), slog.Any("error", err))
+
+			q.track(d, worker.StatusPending, attempts(d), nil)
+
+			q.requeue(name, d)
+
+			return
+		}
+
+		q.Logger.Info("unable to process job", slog.String("job", name), slog.Any("error", err))
+
+		q.retry(queue, name, h.cfg, d, err)
+
+		return
+	}
+
+	q.track(d, worker.StatusSucceeded, attempts(d)+1, nil)
+
+	if err := d.Ack(false); err != nil {
+		q.Logger.Info("unable to ack job", slog.String("job", name), slog.Any("error", err))
+	}
+}
+
+// track records the status of a delivered job.
+func (q *Adapter) track(d amqp.Delivery, status worker.Status, attempt int, jobErr error) {
+	id, _ := d.Headers[jobIDHeader].(string)
+
+	// The status is recorded even when the worker is stopping.
+	ctx := context.WithoutCancel(q.ctx)
+
+	if err := worker.TrackStatus(ctx, q.statusStore, id, status, attempt, jobErr); err != nil {
+		q.Logger.Error("unable to record job status", slog.String("job", id), slog.Any("error", err))
+	}
+}
+
+// startJob tracks a job about to run, unless the adapter is stopping.
+func (q *Adapter) startJob() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case <-q.stopping:
+		return false
+	default:
+	}
+
+	q.jobs.Add(1)
+
+	return true
+}
+
+// requeue returns a delivery to its queue.
+func (q *Adapter) requeue(name string, d amqp.Delivery) {
+	if err := d.Nack(false, true); err != nil {
+		q.Logger.Info("unable to requeue job", slog.String("job", name), slog.Any("error", err))
+	}
+}
+
+// PerformIn performs a job delayed by the given duration, with millisecond
+// precision. A job with a delay that is not positive is performed right
+// away.
+func (q *Adapter) PerformIn(job worker.Job, d time.Duration) error {
+	return q.perform(context.Background(), job, d)
 }
 
-// PerformAt performs a job at the given time.
-func (q Adapter) PerformAt(job worker.Job, t time.Time) error {
+// PerformAt performs a job at the given time, right away when it is past.
+func (q *Adapter) PerformAt(job worker.Job, t time.Time) error {
 	return q.PerformIn(job, time.Until(t))
 }
@@ -0,0 +1,123 @@
+package amqpw
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+const (
+	// deliverAtHeader holds the time a delayed job is due, in unix
+	// milliseconds.
+	deliverAtHeader = "x-deliver-at"
+
+	// delayHeader holds the delay of a job published to the delayed message
+	// exchange, in milliseconds.
+	delayHeader = "x-delay"
+)
+
+// maxDelayBucket is the longest delay queue, RabbitMQ caps x-message-ttl
+// to 2^32-1 milliseconds.
+const maxDelayBucket = (1 << 31) * time.Millisecond
+
+// delayBucket returns the delay queue a job due in d waits in: the longest
+// power of two milliseconds not exceeding d. The job waits in the following
+// buckets, if any, once it expires.
+//
+// Every handler has at most 32 delay queues, and a job expires from at
+// most as many of them as there are bits set in its delay.
+func delayBucket(d time.Duration) time.Duration {
+	if d >= maxDelayBucket {
+		return maxDelayBucket
+	}
+
+	bucket := time.Millisecond
+
+	for bucket*2 <= d {
+		bucket *= 2
+	}
+
+	return bucket
+}
+
+// delayQueueName is the queue where the jobs of a queue wait for delay.
+func delayQueueName(queue string, delay time.Duration) string {
+	return fmt.Sprintf("%s_delay_%d", queue, delay.Milliseconds())
+}
+
+// remaining returns how long the delivered job has to wait until it is
+// due, zero for the jobs that are not delayed.
+func remaining(d amqp.Delivery, now time.Time) time.Duration {
+	deliverAt, ok := int64Header(d.Headers, deliverAtHeader)
+	if !ok {
+		return 0
+	}
+
+	return time.UnixMilli(deliverAt).Sub(now)
+}
+
+// publishDelayed publishes a job to queue, due in delay. With the delayed
+// message exchange, the broker holds the job. Otherwise the job waits in
+// the delay queue of its bucket, then is dead-lettered to queue, whose
+// consumer moves it to the next bucket until it is due.
+func (q *Adapter) publishDelayed(queue string, delay time.Duration, msg amqp.Publishing) error {
+	if q.delayedExchange != "" {
+		msg.Headers[delayHeader] = delay.Milliseconds()
+
+		return q.publishToExchange(q.delayedExchange, queue, msg)
+	}
+
+	msg.Headers[deliverAtHeader] = time.Now().Add(delay).UnixMilli()
+
+	return q.publishToBucket(queue, delay, msg)
+}
+
+// publishToBucket publishes a job of queue to the delay queue of the
+// bucket of delay.
+func (q *Adapter) publishToBucket(queue string, delay time.Duration, msg amqp.Publishing) error {
+	bucket := delayBucket(delay)
+	delayQueue := delayQueueName(queue, bucket)
+
+	_, err := q.Channel.QueueDeclare(
+		delayQueue,
+		true,  // Save on disk
+		false, // Auto-deletion
+		false, // Exclusive
+		false, // No wait
+		amqp.Table{
+			"x-message-ttl":             bucket.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queue,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to declare delay queue: %w", err)
+	}
+
+	return q.publishToQueue(delayQueue, msg)
+}
+
+// delay moves a job delivered from queue that is not due yet to its next
+// delay queue. It reports false when the job is due.
+func (q *Adapter) delay(queue string, d amqp.Delivery) bool {
+	wait := remaining(d, time.Now())
+	if wait < time.Millisecond {
+		return false
+	}
+
+	if err := q.publishToBucket(queue, wait, redelivery(d, d.Headers)); err != nil {
+		q.Logger.Error("unable to delay job", slog.String("queue", queue), slog.Any("error", err))
+
+		q.requeue(queue, d)
+
+		return true
+	}
+
+	if err := d.Ack(false); err != nil {
+		q.Logger.Info("unable to ack job", slog.String("queue", queue), slog.Any("error", err))
+	}
+
+	return true
+}
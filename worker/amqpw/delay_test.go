@@ -0,0 +1,71 @@
+package amqpw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_delayBucket(t *testing.T) {
+	tests := map[string]struct {
+		delay time.Duration
+		want  time.Duration
+	}{
+		"SubMillisecond": {delay: time.Microsecond, want: time.Millisecond},
+		"Millisecond":    {delay: time.Millisecond, want: time.Millisecond},
+		"PowerOfTwo":     {delay: 1024 * time.Millisecond, want: 1024 * time.Millisecond},
+		"Between":        {delay: 1500 * time.Millisecond, want: 1024 * time.Millisecond},
+		"Hour":           {delay: time.Hour, want: 2097152 * time.Millisecond},
+		"Max":            {delay: 365 * 24 * time.Hour, want: maxDelayBucket},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.want, delayBucket(test.delay))
+		})
+	}
+}
+
+// Test_delayBucket_Hops checks that a job goes through a bounded number of
+// delay queues and waits exactly its delay.
+func Test_delayBucket_Hops(t *testing.T) {
+	r := require.New(t)
+
+	for _, delay := range []time.Duration{
+		time.Millisecond,
+		1500 * time.Millisecond,
+		time.Minute + 7*time.Millisecond,
+		36 * time.Hour,
+	} {
+		var (
+			waited time.Duration
+			hops   int
+		)
+
+		for delay-waited >= time.Millisecond {
+			waited += delayBucket(delay - waited)
+			hops++
+		}
+
+		r.Equal(delay, waited)
+		r.LessOrEqual(hops, 32)
+	}
+}
+
+func Test_remaining(t *testing.T) {
+	r := require.New(t)
+
+	now := time.UnixMilli(1_700_000_000_000)
+
+	r.Zero(remaining(amqp.Delivery{}, now))
+
+	r.Equal(250*time.Millisecond, remaining(amqp.Delivery{
+		Headers: amqp.Table{deliverAtHeader: now.Add(250 * time.Millisecond).UnixMilli()},
+	}, now))
+
+	r.Negative(remaining(amqp.Delivery{
+		Headers: amqp.Table{deliverAtHeader: now.Add(-time.Second).UnixMilli()},
+	}, now))
+}
@@ -0,0 +1,62 @@
+package amqpw
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-commons/worker"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+)
+
+// headerCarrier adapts the headers of a message to a TextMapCarrier.
+type headerCarrier amqp.Table
+
+func (c headerCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+
+	for k := range c {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// injectHeaders returns the headers carrying the trace context, with the
+// global propagator, and the request id of ctx.
+func injectHeaders(ctx context.Context) amqp.Table {
+	headers := amqp.Table{}
+
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+
+	if requestID, ok := worker.RequestIDFromContext(ctx); ok {
+		headers[worker.RequestIDHeader] = requestID
+	}
+
+	return headers
+}
+
+// extractContext restores in ctx the trace context and the request id
+// carried by the headers.
+func extractContext(ctx context.Context, headers amqp.Table) context.Context {
+	if headers == nil {
+		return ctx
+	}
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(headers))
+
+	if requestID, ok := headers[worker.RequestIDHeader].(string); ok && requestID != "" {
+		ctx = worker.WithRequestID(ctx, requestID)
+	}
+
+	return ctx
+}
@@ -0,0 +1,236 @@
+package amqpw
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+
+	"github.com/streadway/amqp"
+)
+
+const (
+	// attemptHeader holds the number of times the job was already run.
+	attemptHeader = "x-attempt"
+
+	// lastErrorHeader holds the error of the last attempt of a dead job.
+	lastErrorHeader = "x-last-error"
+
+	// uniqueKeyHeader holds the unique key of a job.
+	uniqueKeyHeader = "x-unique-key"
+
+	// jobIDHeader holds the ID of a job.
+	jobIDHeader = "x-job-id"
+
+	// handlerHeader holds the handler of a job, to dispatch the jobs of
+	// the named queues.
+	handlerHeader = "x-handler"
+)
+
+// HandlerOption configures a handler registered with RegisterHandler.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	retryPolicy worker.RetryPolicy
+	timeout     time.Duration
+	middleware  []worker.Middleware
+	queues      []string
+}
+
+// WithRetryPolicy overrides the retry policy of the adapter for a handler.
+func WithRetryPolicy(policy worker.RetryPolicy) HandlerOption {
+	return func(c *handlerConfig) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithTimeout overrides the job timeout of the adapter for a handler.
+func WithTimeout(timeout time.Duration) HandlerOption {
+	return func(c *handlerConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithQueue consumes the jobs of the handler performed with queue as their
+// Queue. All the handlers of the jobs of a named queue must be registered
+// on it, the jobs of an unknown handler are retried and then dead, in the
+// "<queue>_dead" queue.
+func WithQueue(queue string) HandlerOption {
+	return func(c *handlerConfig) {
+		c.queues = append(c.queues, queue)
+	}
+}
+
+// WithMiddleware wraps a handler with mws, inside the middlewares of the
+// adapter.
+func WithMiddleware(mws ...worker.Middleware) HandlerOption {
+	return func(c *handlerConfig) {
+		c.middleware = append(c.middleware, mws...)
+	}
+}
+
+// deadQueueName is the queue holding the jobs of a queue that exhausted
+// their attempts.
+func deadQueueName(queue string) string {
+	return queue + "_dead"
+}
+
+// retryQueueName is the queue where the jobs of a queue wait for delay
+// before being retried.
+func retryQueueName(queue string, delay time.Duration) string {
+	return fmt.Sprintf("%s_retry_%d", queue, delay.Milliseconds())
+}
+
+// attempts returns the number of times the delivered job was already run.
+func attempts(d amqp.Delivery) int {
+	n, _ := int64Header(d.Headers, attemptHeader)
+
+	return int(n)
+}
+
+// int64Header returns an integer header, whatever its AMQP type.
+func int64Header(headers amqp.Table, key string) (int64, bool) {
+	switch v := headers[key].(type) {
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// retry schedules the failed delivery of a handler for another attempt, or
+// moves it to the dead queue once the attempts are exhausted. The delivery
+// is acked once it was republished, and requeued if that failed.
+//
+// The job is retried through and buried in the queues derived from the
+// queue it was delivered from.
+func (q *Adapter) retry(queue, name string, cfg handlerConfig, d amqp.Delivery, jobErr error) {
+	attempt := attempts(d) + 1
+
+	headers := amqp.Table{}
+
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+
+	headers[attemptHeader] = int32(attempt)
+
+	var err error
+
+	if cfg.retryPolicy.Exhausted(attempt) || worker.IsPermanent(jobErr) {
+		q.track(d, worker.StatusDead, attempt, jobErr)
+
+		q.Logger.Error(
+			"job exhausted its attempts",
+			slog.String("job", name),
+			slog.Int("attempt", attempt),
+			slog.Any("error", jobErr),
+		)
+
+		headers[lastErrorHeader] = jobErr.Error()
+
+		err = q.publishDead(queue, redelivery(d, headers))
+	} else {
+		delay := cfg.retryPolicy.Backoff(attempt)
+
+		q.Logger.Info(
+			"retrying job",
+			slog.String("job", name),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", jobErr),
+		)
+
+		q.track(d, worker.StatusFailed, attempt, jobErr)
+
+		err = q.publishRetry(queue, delay, redelivery(d, headers))
+	}
+
+	if err != nil {
+		q.Logger.Error("unable to retry job", slog.String("job", name), slog.Any("error", err))
+
+		if err := d.Nack(false, true); err != nil {
+			q.Logger.Info("unable to nack job", slog.String("job", name), slog.Any("error", err))
+		}
+
+		return
+	}
+
+	if err := d.Ack(false); err != nil {
+		q.Logger.Info("unable to ack job", slog.String("job", name), slog.Any("error", err))
+	}
+}
+
+// publishRetry publishes the job to a queue whose messages expire after
+// delay and are then dead-lettered back to queue.
+func (q *Adapter) publishRetry(queue string, delay time.Duration, msg amqp.Publishing) error {
+	retryQueue := retryQueueName(queue, delay)
+
+	_, err := q.Channel.QueueDeclare(
+		retryQueue,
+		true,  // Save on disk
+		false, // Auto-deletion
+		false, // Exclusive
+		false, // No wait
+		amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queue,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to declare retry queue: %w", err)
+	}
+
+	return q.publishToQueue(retryQueue, msg)
+}
+
+// publishDead publishes the job to the dead queue of queue.
+func (q *Adapter) publishDead(queue string, msg amqp.Publishing) error {
+	return q.publishToQueue(deadQueueName(queue), msg)
+}
+
+// publishToQueue publishes to a queue through the default exchange.
+func (q *Adapter) publishToQueue(queue string, msg amqp.Publishing) error {
+	return q.publishToExchange("", queue, msg)
+}
+
+// publishToExchange publishes a job to an exchange.
+func (q *Adapter) publishToExchange(exchange, routingKey string, msg amqp.Publishing) error {
+	err := q.Channel.Publish(
+		exchange,   // exchange
+		routingKey, // routing key
+		true,       // mandatory
+		false,      // immediate
+		msg,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to publish to %q: %w", routingKey, err)
+	}
+
+	return nil
+}
+
+// publishing returns the message of a job.
+func publishing(headers amqp.Table, body []byte, priority uint8) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:      headers,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Priority:     priority,
+		Body:         body,
+	}
+}
+
+// redelivery returns the message republishing a delivered job with
+// headers.
+func redelivery(d amqp.Delivery, headers amqp.Table) amqp.Publishing {
+	return publishing(headers, d.Body, d.Priority)
+}
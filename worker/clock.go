@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time to the workers scheduling jobs, it can be replaced
+// by a FakeClock in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After sends the time on the returned channel once d elapsed.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTimer returns a Timer sending the time on its channel once d
+	// elapsed. Unlike After, it can be stopped when its caller stops
+	// waiting.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event of a Clock.
+type Timer interface {
+	// C returns the channel the time is sent on.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+}
+
+// SystemClock returns the Clock of the system.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+// FakeClock is a Clock whose time only moves with Advance and Set.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// fakeTimer is a Timer of a FakeClock, waiting until the clock reaches at.
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	ch    chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+// Stop removes the timer from the waiters of its clock.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, w := range t.clock.waiters {
+		if w == t {
+			t.clock.waiters = append(t.clock.waiters[:i], t.clock.waiters[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+var _ Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After sends the time on the returned channel once the clock is advanced
+// by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer returns a Timer firing once the clock is advanced by d.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, at: c.now.Add(d), ch: make(chan time.Time, 1)}
+
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+
+	c.waiters = append(c.waiters, t)
+
+	return t
+}
+
+// BlockUntil waits until n channels returned by After or NewTimer wait
+// for the clock to be advanced, so a test advancing the clock does not
+// race with the code calling After. The stopped timers are not counted.
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		waiters := len(c.waiters)
+		c.mu.Unlock()
+
+		if waiters >= n {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to now, firing the channels returned by After whose
+// time was reached.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+
+	waiters := c.waiters[:0]
+
+	for _, w := range c.waiters {
+		if now.Before(w.at) {
+			waiters = append(waiters, w)
+			continue
+		}
+
+		w.ch <- now
+	}
+
+	c.waiters = waiters
+}
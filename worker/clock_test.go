@@ -0,0 +1,50 @@
+package worker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock_Timer(t *testing.T) {
+	r := require.New(t)
+
+	clock := worker.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	stopped := clock.NewTimer(time.Minute)
+	timer := clock.NewTimer(time.Hour)
+
+	// A stopped timer is no longer counted by BlockUntil.
+	r.True(stopped.Stop())
+	r.False(stopped.Stop())
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		clock.BlockUntil(2)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("BlockUntil counted a stopped timer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_ = clock.After(time.Minute)
+	<-done
+
+	clock.Advance(time.Hour)
+
+	select {
+	case <-stopped.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+
+	r.Equal(clock.Now(), <-timer.C())
+	r.False(timer.Stop())
+}
@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes the payloads of the typed jobs.
+type Codec interface {
+	// Name identifies the codec in the encoded args.
+	Name() string
+
+	// Marshal encodes v.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes data into v, a pointer.
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec encodes the payloads with encoding/json.
+type JSONCodec struct{}
+
+// Name returns "json".
+func (JSONCodec) Name() string { return "json" }
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into v.
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtoCodec encodes the payloads, protobuf messages, in the protobuf
+// binary format.
+type ProtoCodec struct{}
+
+// Name returns "proto".
+func (ProtoCodec) Name() string { return "proto" }
+
+// Marshal encodes v, a proto.Message.
+func (ProtoCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message", v)
+	}
+
+	return proto.Marshal(m)
+}
+
+// Unmarshal decodes data into v, a proto.Message or a pointer to a nil one,
+// which is allocated.
+func (ProtoCodec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Pointer {
+		return fmt.Errorf("%T is not a pointer to a proto.Message", v)
+	}
+
+	elem := rv.Elem()
+
+	if elem.IsNil() {
+		elem.Set(reflect.New(elem.Type().Elem()))
+	}
+
+	m, ok := elem.Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a pointer to a proto.Message", v)
+	}
+
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the header carrying the request id, the same as the
+// one used by grpcutils.
+const RequestIDHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request id. The id is
+// also set as incoming grpc metadata, so grpcutils.GetRequestIDFromCtx finds
+// it in the handlers.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	md = md.Copy()
+	md.Set(RequestIDHeader, requestID)
+
+	return context.WithValue(
+		metadata.NewIncomingContext(ctx, md),
+		requestIDKey{},
+		requestID,
+	)
+}
+
+// RequestIDFromContext returns the request id set with WithRequestID, or
+// else the one of the incoming grpc metadata.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if requestID, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return requestID, true
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	requestIDs := md.Get(RequestIDHeader)
+	if len(requestIDs) != 1 {
+		return "", false
+	}
+
+	return requestIDs[0], true
+}
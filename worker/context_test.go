@@ -0,0 +1,36 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDFromContext(t *testing.T) {
+	r := require.New(t)
+
+	_, ok := worker.RequestIDFromContext(context.Background())
+	r.False(ok)
+
+	// The request id of an incoming grpc request.
+	ctx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs(worker.RequestIDHeader, "grpc-id"),
+	)
+
+	requestID, ok := worker.RequestIDFromContext(ctx)
+	r.True(ok)
+	r.Equal("grpc-id", requestID)
+
+	ctx = worker.WithRequestID(ctx, "job-id")
+
+	requestID, ok = worker.RequestIDFromContext(ctx)
+	r.True(ok)
+	r.Equal("job-id", requestID)
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.Equal([]string{"job-id"}, md.Get(worker.RequestIDHeader))
+}
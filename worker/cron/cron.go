@@ -0,0 +1,331 @@
+// Package cron performs recurring jobs, on cron expressions or fixed
+// intervals, with any worker.Worker.
+//
+// Every replica of a service may run the same schedules: a TickStore shared
+// by the replicas makes sure each tick performs its job once.
+//
+// A tick is claimed before its job is performed, and performing it is
+// retried following the RetryPolicy of the scheduler. A tick whose job
+// could not be performed once the retries are exhausted is lost: ticks are
+// delivered at most once.
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// CatchUpPolicy tells what happens to the ticks missed while no replica
+// was running.
+type CatchUpPolicy int
+
+const (
+	// CatchUpNone skips the missed ticks.
+	CatchUpNone CatchUpPolicy = iota
+	// CatchUpLatest performs the job once for the missed ticks.
+	CatchUpLatest
+	// CatchUpAll performs the job for every missed tick, up to
+	// MaxCatchUp ticks.
+	CatchUpAll
+)
+
+// MaxCatchUp bounds the missed ticks performed with CatchUpAll, the most
+// recent ones are kept.
+const MaxCatchUp = 1000
+
+// Options are used to configure the scheduler.
+type Options struct {
+	// Logger is a logger interface to write the scheduler logs.
+	Logger *slog.Logger
+
+	// Store records the ticks that ran. It must be shared by the replicas
+	// running the same schedules. Defaults to a MemoryStore.
+	Store TickStore
+
+	// Clock tells the time of the ticks. Defaults to worker.SystemClock.
+	Clock worker.Clock
+
+	// RetryPolicy configures how performing the job of a claimed tick is
+	// retried when the worker fails to enqueue it. Defaults to
+	// worker.DefaultRetryPolicy.
+	RetryPolicy worker.RetryPolicy
+}
+
+// ErrDuplicateEntry is returned when two entries have the same name.
+var ErrDuplicateEntry = errors.New("duplicate entry")
+
+// EntryOption configures an entry added with Add.
+type EntryOption func(*entry)
+
+// WithCatchUp sets the catch-up policy of an entry. Defaults to
+// CatchUpNone.
+func WithCatchUp(policy CatchUpPolicy) EntryOption {
+	return func(e *entry) {
+		e.catchUp = policy
+	}
+}
+
+type entry struct {
+	name     string
+	schedule Schedule
+	job      worker.Job
+	catchUp  CatchUpPolicy
+}
+
+// Scheduler performs the jobs of its entries at their ticks.
+type Scheduler struct {
+	worker worker.Worker
+	logger *slog.Logger
+	store  TickStore
+	clock  worker.Clock
+	retry  worker.RetryPolicy
+
+	// mu guards the fields below.
+	mu      sync.Mutex
+	entries []*entry
+	// ctx is the context of the entries, set by Start.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wg sync.WaitGroup
+}
+
+// New creates a scheduler performing its jobs with w.
+func New(w worker.Worker, opts Options) *Scheduler {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = worker.SystemClock()
+	}
+
+	if opts.RetryPolicy.MaxAttempts == 0 {
+		opts.RetryPolicy = worker.DefaultRetryPolicy()
+	}
+
+	return &Scheduler{
+		worker: w,
+		logger: opts.Logger,
+		store:  opts.Store,
+		clock:  opts.Clock,
+		retry:  opts.RetryPolicy,
+	}
+}
+
+// Add performs job on the ticks of schedule. The name identifies the entry
+// in the TickStore, it must be the same on every replica.
+func (s *Scheduler) Add(name string, schedule Schedule, job worker.Job, opts ...EntryOption) error {
+	e := &entry{
+		name:     name,
+		schedule: schedule,
+		job:      job,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.entries {
+		if existing.name == name {
+			return fmt.Errorf("%w: %q", ErrDuplicateEntry, name)
+		}
+	}
+
+	s.entries = append(s.entries, e)
+
+	if s.ctx != nil {
+		s.startEntry(e)
+	}
+
+	return nil
+}
+
+// Start runs the entries until ctx is done or Stop is called. The missed
+// ticks are caught up first, following the policy of each entry.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx != nil {
+		return nil
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, e := range s.entries {
+		s.startEntry(e)
+	}
+
+	return nil
+}
+
+// Stop stops the entries and waits for them to return.
+func (s *Scheduler) Stop() error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	s.wg.Wait()
+
+	return nil
+}
+
+// startEntry runs an entry in the background, s.mu must be held.
+func (s *Scheduler) startEntry(e *entry) {
+	s.wg.Add(1)
+
+	go func(ctx context.Context) {
+		defer s.wg.Done()
+
+		s.run(ctx, e)
+	}(s.ctx)
+}
+
+// run catches up the missed ticks of the entry, then performs its job on
+// every tick until ctx is done.
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	cursor := s.catchUp(ctx, e)
+
+	for {
+		next := e.schedule.Next(cursor)
+
+		timer := s.clock.NewTimer(next.Sub(s.clock.Now()))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		// When the scheduler is late, the ticks that passed meanwhile are
+		// missed: only CatchUpAll performs them.
+		if e.catchUp != CatchUpAll {
+			now := s.clock.Now()
+
+			for n := e.schedule.Next(next); !n.After(now); n = e.schedule.Next(n) {
+				next = n
+			}
+		}
+
+		s.fire(ctx, e, next)
+
+		cursor = next
+	}
+}
+
+// catchUp performs the ticks missed since the last claimed one, following
+// the policy of the entry. It returns the time after which the next tick
+// is looked for.
+func (s *Scheduler) catchUp(ctx context.Context, e *entry) time.Time {
+	now := s.clock.Now()
+
+	if e.catchUp == CatchUpNone {
+		return now
+	}
+
+	last, ok, err := s.store.Last(ctx, e.name)
+	if err != nil {
+		s.logger.Error("unable to get last tick", slog.String("entry", e.name), slog.Any("error", err))
+		return now
+	}
+
+	if !ok {
+		return now
+	}
+
+	var missed []time.Time
+
+	for t := e.schedule.Next(last); !t.After(now); t = e.schedule.Next(t) {
+		missed = append(missed, t)
+
+		if len(missed) > MaxCatchUp {
+			missed = missed[1:]
+		}
+	}
+
+	if len(missed) == 0 {
+		return now
+	}
+
+	if e.catchUp == CatchUpLatest {
+		missed = missed[len(missed)-1:]
+	}
+
+	s.logger.Info("catching up missed ticks", slog.String("entry", e.name), slog.Int("ticks", len(missed)))
+
+	for _, t := range missed {
+		s.fire(ctx, e, t)
+	}
+
+	return now
+}
+
+// fire performs the job of the tick, if this replica claims it. The tick
+// is claimed by this replica only, so performing its job is retried until
+// the retry policy is exhausted.
+func (s *Scheduler) fire(ctx context.Context, e *entry, tick time.Time) {
+	claimed, err := s.store.Claim(ctx, e.name, tick)
+	if err != nil {
+		s.logger.Error("unable to claim tick", slog.String("entry", e.name), slog.Any("error", err))
+		return
+	}
+
+	if !claimed {
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := worker.PerformContext(ctx, s.worker, e.job)
+		if err == nil {
+			return
+		}
+
+		if s.retry.Exhausted(attempt) || ctx.Err() != nil {
+			s.logger.Error(
+				"unable to perform job, the tick is lost",
+				slog.String("entry", e.name),
+				slog.Time("tick", tick),
+				slog.Int("attempts", attempt),
+				slog.Any("error", err),
+			)
+
+			return
+		}
+
+		s.logger.Warn(
+			"unable to perform job, retrying",
+			slog.String("entry", e.name),
+			slog.Time("tick", tick),
+			slog.Int("attempt", attempt),
+			slog.Any("error", err),
+		)
+
+		timer := s.clock.NewTimer(s.retry.Backoff(attempt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
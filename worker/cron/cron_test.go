@@ -0,0 +1,209 @@
+package cron_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/cron"
+	"github.com/purposeinplay/go-commons/worker/memw"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC)
+
+func TestCron_Location(t *testing.T) {
+	r := require.New(t)
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+
+	schedule, err := cron.Cron("0 9 * * *", loc)
+	r.NoError(err)
+
+	r.Equal(
+		time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC),
+		schedule.Next(start).UTC(),
+	)
+
+	_, err = cron.Cron("not a cron", nil)
+	r.Error(err)
+}
+
+func TestEvery(t *testing.T) {
+	r := require.New(t)
+
+	schedule, err := cron.Every(time.Minute)
+	r.NoError(err)
+
+	r.Equal(start.Add(30*time.Second), schedule.Next(start))
+
+	// The ticks are aligned on the Unix epoch, whatever the interval.
+	schedule, err = cron.Every(7 * time.Minute)
+	r.NoError(err)
+
+	next := schedule.Next(start)
+	r.True(next.After(start))
+	r.Zero(next.Sub(time.Unix(0, 0)) % (7 * time.Minute))
+	r.Equal(next.Add(7*time.Minute), schedule.Next(next))
+
+	_, err = cron.Every(0)
+	r.ErrorIs(err, cron.ErrInvalidInterval)
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	r := require.New(t)
+
+	clock := worker.NewFakeClock(start)
+	w := memw.New(memw.Options{Clock: clock})
+	store := cron.NewMemoryStore()
+
+	schedule, err := cron.Every(time.Minute)
+	r.NoError(err)
+
+	// Two replicas running the same schedule.
+	for i := 0; i < 2; i++ {
+		s := cron.New(w, cron.Options{Store: store, Clock: clock})
+
+		r.NoError(s.Add("report", schedule, worker.Job{Handler: "report"}))
+		r.NoError(s.Start(context.Background()))
+
+		defer s.Stop()
+	}
+
+	r.Empty(w.Enqueued("report"))
+
+	clock.BlockUntil(2)
+	clock.Advance(30 * time.Second)
+
+	r.Eventually(func() bool {
+		return len(w.Enqueued("report")) == 1
+	}, time.Second, time.Millisecond)
+
+	clock.BlockUntil(2)
+	clock.Advance(time.Minute)
+
+	r.Eventually(func() bool {
+		return len(w.Enqueued("report")) == 2
+	}, time.Second, time.Millisecond)
+
+	// Each tick is performed by one replica only.
+	r.Never(func() bool {
+		return len(w.Enqueued("report")) > 2
+	}, 50*time.Millisecond, time.Millisecond)
+}
+
+func TestScheduler_CatchUp(t *testing.T) {
+	tests := map[string]struct {
+		policy cron.CatchUpPolicy
+		want   int
+	}{
+		"None": {
+			policy: cron.CatchUpNone,
+			want:   0,
+		},
+		"Latest": {
+			policy: cron.CatchUpLatest,
+			want:   1,
+		},
+		"All": {
+			policy: cron.CatchUpAll,
+			want:   5,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+
+			ctx := context.Background()
+
+			clock := worker.NewFakeClock(start)
+			w := memw.New(memw.Options{Clock: clock})
+			store := cron.NewMemoryStore()
+
+			// The last tick ran 5 minutes before the replicas restarted.
+			_, err := store.Claim(ctx, "report", start.Truncate(time.Minute).Add(-5*time.Minute))
+			r.NoError(err)
+
+			schedule, err := cron.Every(time.Minute)
+			r.NoError(err)
+
+			s := cron.New(w, cron.Options{Store: store, Clock: clock})
+
+			r.NoError(s.Add("report", schedule, worker.Job{Handler: "report"}, cron.WithCatchUp(test.policy)))
+			r.NoError(s.Start(ctx))
+
+			defer s.Stop()
+
+			r.Eventually(func() bool {
+				return len(w.Enqueued("report")) == test.want
+			}, time.Second, time.Millisecond)
+
+			last, ok, err := store.Last(ctx, "report")
+			r.NoError(err)
+			r.True(ok)
+
+			if test.want > 0 {
+				r.Equal(start.Truncate(time.Minute), last)
+			}
+		})
+	}
+}
+
+// flakyWorker fails to perform the first failures jobs.
+type flakyWorker struct {
+	worker.Worker
+	failures atomic.Int32
+}
+
+var errEnqueue = errors.New("enqueue failed")
+
+func (w *flakyWorker) Perform(job worker.Job) error {
+	if w.failures.Add(-1) >= 0 {
+		return errEnqueue
+	}
+
+	return w.Worker.Perform(job)
+}
+
+func TestScheduler_RetryPerform(t *testing.T) {
+	r := require.New(t)
+
+	clock := worker.NewFakeClock(start)
+	w := memw.New(memw.Options{Clock: clock})
+
+	flaky := &flakyWorker{Worker: w}
+	flaky.failures.Store(2)
+
+	schedule, err := cron.Every(time.Minute)
+	r.NoError(err)
+
+	s := cron.New(flaky, cron.Options{
+		Clock: clock,
+		RetryPolicy: worker.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Second,
+		},
+	})
+
+	r.NoError(s.Add("report", schedule, worker.Job{Handler: "report"}))
+	r.NoError(s.Start(context.Background()))
+
+	defer s.Stop()
+
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+
+	// The claimed tick is performed once the worker recovers.
+	for range 2 {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+
+	r.Eventually(func() bool {
+		return len(w.Enqueued("report")) == 1
+	}, time.Second, time.Millisecond)
+}
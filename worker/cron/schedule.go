@@ -0,0 +1,66 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	robfigcron "github.com/robfig/cron/v3"
+)
+
+// Schedule tells when a recurring job runs.
+type Schedule interface {
+	// Next returns the first tick strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// ErrInvalidInterval is returned by Every for a non-positive interval.
+var ErrInvalidInterval = errors.New("invalid interval")
+
+type cronSchedule struct {
+	schedule robfigcron.Schedule
+	location *time.Location
+}
+
+// Cron parses a standard cron expression, with 5 fields or a descriptor
+// such as "@daily", evaluated in loc. A nil loc means UTC.
+func Cron(expr string, loc *time.Location) (Schedule, error) {
+	schedule, err := robfigcron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parse cron expression %q: %w", expr, err)
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return cronSchedule{schedule: schedule, location: loc}, nil
+}
+
+func (s cronSchedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t.In(s.location))
+}
+
+type everySchedule struct {
+	interval time.Duration
+}
+
+// Every ticks at a fixed interval. The ticks are aligned on the Unix epoch,
+// so all the replicas agree on them.
+func Every(interval time.Duration) (Schedule, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	return everySchedule{interval: interval}, nil
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	// time.Truncate would align on the zero time, not on the Unix epoch.
+	since := t.Sub(time.Unix(0, 0)) % s.interval
+	if since < 0 {
+		since += s.interval
+	}
+
+	return t.Add(s.interval - since)
+}
@@ -0,0 +1,138 @@
+package cron
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TickStore records the ticks that ran. It is the leader lock of the
+// replicas running the same schedules: a tick runs on the replica that
+// claims it first. A claimed tick is never claimed again, even when its
+// job could not be performed.
+type TickStore interface {
+	// Claim records that the tick of the named schedule runs. It reports
+	// false when the tick, or a later one, was already claimed.
+	Claim(ctx context.Context, name string, tick time.Time) (bool, error)
+
+	// Last returns the last claimed tick of the named schedule, ok is false
+	// when none was claimed.
+	Last(ctx context.Context, name string) (tick time.Time, ok bool, err error)
+}
+
+// MemoryStore is a TickStore for a single replica, the ticks it records
+// are lost on restart.
+type MemoryStore struct {
+	mu    sync.Mutex
+	ticks map[string]time.Time
+}
+
+var _ TickStore = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{ticks: make(map[string]time.Time)}
+}
+
+// Claim records the tick if it is after the last one.
+func (s *MemoryStore) Claim(_ context.Context, name string, tick time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.ticks[name]; ok && !last.Before(tick) {
+		return false, nil
+	}
+
+	s.ticks[name] = tick
+
+	return true, nil
+}
+
+// Last returns the last claimed tick.
+func (s *MemoryStore) Last(_ context.Context, name string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, ok := s.ticks[name]
+
+	return last, ok, nil
+}
+
+// DefaultTicksTable is the name of the table of PGStore.
+const DefaultTicksTable = "worker_cron_ticks"
+
+// PGStore is a TickStore keeping the last tick of every schedule in a
+// PostgreSQL table, shared by the replicas.
+type PGStore struct {
+	db    *sql.DB
+	table string
+}
+
+var _ TickStore = (*PGStore)(nil)
+
+// NewPGStore returns a PGStore on table, DefaultTicksTable when empty.
+func NewPGStore(db *sql.DB, table string) *PGStore {
+	if table == "" {
+		table = DefaultTicksTable
+	}
+
+	return &PGStore{db: db, table: table}
+}
+
+// Migrate creates the ticks table if it does not exist.
+func (s *PGStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	name      TEXT PRIMARY KEY,
+	last_tick TIMESTAMPTZ NOT NULL
+)`, s.table))
+	if err != nil {
+		return fmt.Errorf("create ticks table: %w", err)
+	}
+
+	return nil
+}
+
+// Claim records the tick if it is after the last one, atomically.
+func (s *PGStore) Claim(ctx context.Context, name string, tick time.Time) (bool, error) {
+	res, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %[1]s (name, last_tick) VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET last_tick = EXCLUDED.last_tick
+WHERE %[1]s.last_tick < EXCLUDED.last_tick`, s.table),
+		name,
+		tick,
+	)
+	if err != nil {
+		return false, fmt.Errorf("claim tick: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("claim tick: %w", err)
+	}
+
+	return n == 1, nil
+}
+
+// Last returns the last claimed tick.
+func (s *PGStore) Last(ctx context.Context, name string) (time.Time, bool, error) {
+	var last time.Time
+
+	err := s.db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(`SELECT last_tick FROM %s WHERE name = $1`, s.table),
+		name,
+	).Scan(&last)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return time.Time{}, false, nil
+	case err != nil:
+		return time.Time{}, false, fmt.Errorf("get last tick: %w", err)
+	}
+
+	return last, true, nil
+}
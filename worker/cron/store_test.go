@@ -0,0 +1,68 @@
+package cron_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker/cron"
+	"github.com/purposeinplay/go-commons/worker/internal/pgtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPGStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := pgtest.Store(t, "cron_ticks", cron.NewPGStore)
+
+	_, ok, err := store.Last(ctx, "report")
+	r.NoError(err)
+	r.False(ok)
+
+	claimed, err := store.Claim(ctx, "report", start)
+	r.NoError(err)
+	r.True(claimed)
+
+	// The same tick, or an earlier one, is claimed once.
+	claimed, err = store.Claim(ctx, "report", start)
+	r.NoError(err)
+	r.False(claimed)
+
+	claimed, err = store.Claim(ctx, "report", start.Add(-time.Minute))
+	r.NoError(err)
+	r.False(claimed)
+
+	claimed, err = store.Claim(ctx, "report", start.Add(time.Minute))
+	r.NoError(err)
+	r.True(claimed)
+
+	last, ok, err := store.Last(ctx, "report")
+	r.NoError(err)
+	r.True(ok)
+	r.True(start.Add(time.Minute).Equal(last))
+}
+
+func TestPGStore_ConcurrentClaim(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := pgtest.Store(t, "cron_ticks", cron.NewPGStore)
+
+	const replicas = 10
+
+	var claimed atomic.Int32
+
+	// Every replica claims the same tick, only one of them runs it.
+	pgtest.Concurrently(t, replicas, func(int) error {
+		ok, err := store.Claim(ctx, "report", start)
+		if ok {
+			claimed.Add(1)
+		}
+
+		return err
+	})
+
+	r.Equal(int32(1), claimed.Load())
+}
@@ -1,6 +1,9 @@
 package worker
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Args are the arguments passed into a job.
 type Args map[string]interface{}
@@ -12,12 +15,26 @@ func (a Args) String() string {
 
 // Job to be processed by a Worker.
 type Job struct {
+	// ID identifies the job in a StatusStore, the workers generate it when
+	// empty
+	ID string `json:",omitempty"`
 	// Handler that will be run by the worker
 	Handler string
 	// Queue the job should be placed into
 	Queue string
 	// Args that will be passed to the Handler when run
 	Args Args
+	// Priority orders the due jobs, the ones with a higher priority run
+	// first
+	Priority uint8 `json:",omitempty"`
+	// UniqueKey makes the job unique: while a job of the same Handler with
+	// the same UniqueKey is pending, the job is a duplicate
+	UniqueKey string `json:",omitempty"`
+	// UniqueFor bounds how long a pending job blocks its duplicates,
+	// defaults to DefaultUniqueFor
+	UniqueFor time.Duration `json:",omitempty"`
+	// OnDuplicate tells whether a duplicate is rejected or coalesced
+	OnDuplicate DuplicatePolicy `json:",omitempty"`
 }
 
 func (j Job) String() string {
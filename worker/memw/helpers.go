@@ -0,0 +1,111 @@
+package memw
+
+import (
+	"context"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// DeadJob is a job that exhausted its attempts.
+type DeadJob struct {
+	Job      worker.Job
+	Attempts int
+	Err      error
+}
+
+// Enqueued returns the jobs of handler waiting to run, including the
+// scheduled ones and the ones waiting for a retry, in the order they run.
+func (w *Worker) Enqueued(handler string) []worker.Job {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.sortPendingLocked()
+
+	var jobs []worker.Job
+
+	for _, e := range w.pending {
+		if e.job.Handler == handler {
+			jobs = append(jobs, e.job)
+		}
+	}
+
+	return jobs
+}
+
+// Dead returns the jobs of handler that exhausted their attempts.
+func (w *Worker) Dead(handler string) []DeadJob {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var jobs []DeadJob
+
+	for _, e := range w.dead {
+		if e.job.Handler == handler {
+			jobs = append(jobs, DeadJob{
+				Job:      e.job,
+				Attempts: e.attempts,
+				Err:      e.lastErr,
+			})
+		}
+	}
+
+	return jobs
+}
+
+// RunUntilIdle runs the due jobs, and the ones they perform, until no job
+// is due or running. The scheduled jobs stay enqueued, advance the clock
+// to run them.
+func (w *Worker) RunUntilIdle(ctx context.Context) error {
+	return w.runUntilIdle(ctx, false)
+}
+
+// Drain runs all the jobs, including the scheduled ones and the retries
+// regardless of their time, until none is left. It returns when no job is
+// enqueued or running, except the ones without a registered handler.
+func (w *Worker) Drain(ctx context.Context) error {
+	return w.runUntilIdle(ctx, true)
+}
+
+func (w *Worker) runUntilIdle(ctx context.Context, ignoreSchedule bool) error {
+	for {
+		w.mu.Lock()
+
+		if w.stopped {
+			w.mu.Unlock()
+			return ErrStopped
+		}
+
+		w.startDueLocked(ignoreSchedule)
+
+		idle := w.running == 0 && !w.hasDueLocked(ignoreSchedule)
+		changed := w.changed
+		w.mu.Unlock()
+
+		if idle {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// hasDueLocked tells whether a job with a registered handler is due.
+func (w *Worker) hasDueLocked(ignoreSchedule bool) bool {
+	now := w.clock.Now()
+
+	for _, e := range w.pending {
+		if _, registered := w.handlers[e.job.Handler]; !registered {
+			continue
+		}
+
+		if ignoreSchedule || !e.runAt.After(now) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,548 @@
+// Package memw implements worker.Worker in memory, to test the code
+// performing jobs and to run it locally without a broker.
+package memw
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/middleware"
+	"github.com/purposeinplay/go-commons/worker/unique"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options are used to configure the in-memory worker.
+type Options struct {
+	// Logger is a logger interface to write the worker logs.
+	Logger *slog.Logger
+
+	// MaxConcurrency restricts the amount of workers in parallel.
+	MaxConcurrency int
+
+	// RetryPolicy configures how the failed jobs are retried, it can be
+	// overridden per handler with WithRetryPolicy. Defaults to
+	// worker.DefaultRetryPolicy.
+	RetryPolicy worker.RetryPolicy
+
+	// JobTimeout cancels the context of a job that runs longer, it can be
+	// overridden per handler with WithTimeout. Zero means no timeout.
+	JobTimeout time.Duration
+
+	// Middleware wraps every handler, outside the middlewares given with
+	// WithMiddleware. The first middleware is the outermost one. The
+	// handlers are always wrapped with middleware.Recover first, so a
+	// panicking job fails and is retried instead of crashing the worker.
+	Middleware []worker.Middleware
+
+	// Clock schedules the jobs performed with PerformAt and PerformIn and
+	// the retries. Defaults to worker.SystemClock.
+	Clock worker.Clock
+
+	// UniqueStore reserves the keys of the unique jobs, see
+	// worker.Job.UniqueKey. Defaults to a unique.MemoryStore on Clock.
+	UniqueStore worker.UniqueStore
+
+	// StatusStore records the state of the jobs, see the status package.
+	// Defaults to nil, the state is not recorded.
+	StatusStore worker.StatusStore
+}
+
+// ErrStopped is returned when a job is performed after Stop.
+var ErrStopped = errors.New("worker stopped")
+
+// Ensures Worker implements the Worker interface.
+var _ worker.ContextWorker = &Worker{}
+
+// Worker runs the jobs in memory.
+//
+// Jobs are run in the background once the worker is started. In tests,
+// RunUntilIdle and Drain run them without starting the worker.
+type Worker struct {
+	logger         *slog.Logger
+	maxConcurrency int
+	retryPolicy    worker.RetryPolicy
+	jobTimeout     time.Duration
+	middleware     []worker.Middleware
+	clock          worker.Clock
+	uniqueStore    worker.UniqueStore
+	statusStore    worker.StatusStore
+
+	// mu guards the fields below.
+	mu       sync.Mutex
+	ctx      context.Context
+	handlers map[string]handler
+	pending  []*entry
+	dead     []*entry
+	running  int
+	seq      uint64
+	stopped  bool
+	// changed is closed and replaced whenever the state changes.
+	changed chan struct{}
+	// stop is closed by Stop.
+	stop chan struct{}
+	// wg tracks the running jobs and the dispatcher.
+	wg sync.WaitGroup
+}
+
+type handler struct {
+	h   worker.ContextHandler
+	cfg handlerConfig
+}
+
+// entry is a job waiting to run.
+type entry struct {
+	job worker.Job
+	// ctx carries the trace context and request id the job was performed
+	// with.
+	ctx   context.Context
+	runAt time.Time
+	// attempts is the number of times the job was already run.
+	attempts int
+	// seq orders the jobs due at the same time.
+	seq     uint64
+	lastErr error
+}
+
+// New creates a new in-memory worker.
+func New(opts Options) *Worker {
+	if opts.MaxConcurrency == 0 {
+		opts.MaxConcurrency = 25
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	if opts.RetryPolicy.MaxAttempts == 0 {
+		opts.RetryPolicy = worker.DefaultRetryPolicy()
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = worker.SystemClock()
+	}
+
+	if opts.UniqueStore == nil {
+		opts.UniqueStore = unique.NewMemoryStore(opts.Clock)
+	}
+
+	return &Worker{
+		logger:         opts.Logger,
+		maxConcurrency: opts.MaxConcurrency,
+		retryPolicy:    opts.RetryPolicy,
+		jobTimeout:     opts.JobTimeout,
+		middleware:     opts.Middleware,
+		clock:          opts.Clock,
+		uniqueStore:    opts.UniqueStore,
+		statusStore:    opts.StatusStore,
+		ctx:            context.Background(),
+		handlers:       make(map[string]handler),
+		changed:        make(chan struct{}),
+		stop:           make(chan struct{}),
+	}
+}
+
+// Start runs the jobs in the background until ctx is done or Stop is
+// called. The context of the jobs derives from ctx.
+func (w *Worker) Start(ctx context.Context) error {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return ErrStopped
+	}
+	w.ctx = ctx
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		w.dispatch(ctx)
+	}()
+
+	return nil
+}
+
+// dispatch starts the due jobs whenever the state changes or a scheduled
+// job becomes due.
+func (w *Worker) dispatch(ctx context.Context) {
+	for {
+		w.mu.Lock()
+		w.startDueLocked(false)
+		next, scheduled := w.nextRunAtLocked()
+		changed := w.changed
+		w.mu.Unlock()
+
+		var (
+			due   <-chan time.Time
+			timer worker.Timer
+		)
+
+		if scheduled {
+			timer = w.clock.NewTimer(next.Sub(w.clock.Now()))
+			due = timer.C()
+		}
+
+		var done bool
+
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-w.stop:
+			done = true
+		case <-changed:
+		case <-due:
+		}
+
+		// The timer is superseded by the next iteration.
+		if timer != nil {
+			timer.Stop()
+		}
+
+		if done {
+			return
+		}
+	}
+}
+
+// Stop stops starting jobs and waits for the running ones to return.
+func (w *Worker) Stop() error {
+	w.logger.Info("stopping in-memory worker")
+
+	w.mu.Lock()
+	if !w.stopped {
+		w.stopped = true
+		close(w.stop)
+	}
+	w.mu.Unlock()
+
+	w.wg.Wait()
+
+	return nil
+}
+
+// Perform enqueues a new job.
+func (w *Worker) Perform(job worker.Job) error {
+	return w.PerformContext(context.Background(), job)
+}
+
+// PerformContext enqueues a new job, the trace context and request id of
+// ctx are restored in the context of the handler.
+func (w *Worker) PerformContext(ctx context.Context, job worker.Job) error {
+	return w.enqueue(ctx, job, w.clock.Now())
+}
+
+// PerformIn performs a job delayed by the given duration.
+func (w *Worker) PerformIn(job worker.Job, d time.Duration) error {
+	return w.enqueue(context.Background(), job, w.clock.Now().Add(d))
+}
+
+// PerformAt performs a job at the given time.
+func (w *Worker) PerformAt(job worker.Job, t time.Time) error {
+	return w.enqueue(context.Background(), job, t)
+}
+
+func (w *Worker) enqueue(ctx context.Context, job worker.Job, runAt time.Time) error {
+	w.logger.Info("enqueuing job", slog.Any("job", job))
+
+	// Pass the args through JSON like the brokers do, so the handlers see
+	// the same types.
+	args, err := roundTrip(job.Args)
+	if err != nil {
+		return fmt.Errorf("error enqueuing job: %w", err)
+	}
+
+	job.Args = args
+
+	if job.ID == "" {
+		job.ID = worker.NewJobID()
+	}
+
+	enqueue, err := worker.AcquireUnique(ctx, w.uniqueStore, job)
+	if err != nil || !enqueue {
+		return err
+	}
+
+	// Record the job before it can run.
+	if err := worker.TrackEnqueued(ctx, w.statusStore, job); err != nil {
+		w.logger.Error("unable to record job", slog.Any("job", job), slog.Any("error", err))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped {
+		_ = worker.ReleaseUnique(ctx, w.uniqueStore, job)
+		_ = worker.Untrack(ctx, w.statusStore, job.ID)
+
+		return ErrStopped
+	}
+
+	w.seq++
+
+	w.pending = append(w.pending, &entry{
+		job:   job,
+		ctx:   propagated(ctx),
+		runAt: runAt,
+		seq:   w.seq,
+	})
+
+	w.notifyLocked()
+
+	return nil
+}
+
+func roundTrip(args worker.Args) (worker.Args, error) {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	out := worker.Args{}
+
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// propagated returns a context carrying only the trace context and request
+// id of ctx, the values a broker would propagate.
+func propagated(ctx context.Context) context.Context {
+	out := trace.ContextWithRemoteSpanContext(
+		context.Background(),
+		trace.SpanContextFromContext(ctx),
+	)
+
+	if requestID, ok := worker.RequestIDFromContext(ctx); ok {
+		out = worker.WithRequestID(out, requestID)
+	}
+
+	return out
+}
+
+// Register a Handler.
+func (w *Worker) Register(name string, h worker.Handler) error {
+	return w.RegisterHandler(name, worker.AdaptHandler(h))
+}
+
+// RegisterContext registers a ContextHandler.
+func (w *Worker) RegisterContext(name string, h worker.ContextHandler) error {
+	return w.RegisterHandler(name, h)
+}
+
+// RegisterHandler registers a ContextHandler configured with opts. The
+// jobs performed before their handler is registered wait for it.
+//
+// A job whose handler fails is retried with the backoff of the retry
+// policy, once its attempts are exhausted it is dead.
+func (w *Worker) RegisterHandler(name string, h worker.ContextHandler, opts ...HandlerOption) error {
+	w.logger.Info("register job", slog.String("job", name))
+
+	cfg := handlerConfig{
+		retryPolicy: w.retryPolicy,
+		timeout:     w.jobTimeout,
+		middleware:  slices.Clone(w.middleware),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	h = worker.Chain(h, slices.Insert(cfg.middleware, 0, middleware.Recover())...)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handlers[name] = handler{h: h, cfg: cfg}
+
+	w.notifyLocked()
+
+	return nil
+}
+
+// startDueLocked starts the due jobs with a registered handler, up to the
+// max concurrency. When ignoreSchedule is true, the scheduled jobs are due.
+func (w *Worker) startDueLocked(ignoreSchedule bool) {
+	if w.stopped {
+		return
+	}
+
+	now := w.clock.Now()
+
+	w.sortPendingLocked()
+
+	pending := w.pending[:0]
+
+	for _, e := range w.pending {
+		h, registered := w.handlers[e.job.Handler]
+
+		due := ignoreSchedule || !e.runAt.After(now)
+
+		if !registered || !due || w.running >= w.maxConcurrency {
+			pending = append(pending, e)
+			continue
+		}
+
+		w.running++
+		w.wg.Add(1)
+
+		go w.run(w.ctx, e, h)
+	}
+
+	w.pending = pending
+}
+
+// sortPendingLocked sorts the pending jobs in the order they run once
+// due: by priority, then by time.
+func (w *Worker) sortPendingLocked() {
+	sort.SliceStable(w.pending, func(i, j int) bool {
+		a, b := w.pending[i], w.pending[j]
+
+		if a.job.Priority != b.job.Priority {
+			return a.job.Priority > b.job.Priority
+		}
+
+		if !a.runAt.Equal(b.runAt) {
+			return a.runAt.Before(b.runAt)
+		}
+
+		return a.seq < b.seq
+	})
+}
+
+// nextRunAtLocked returns the time of the next scheduled job. The due
+// jobs waiting for a free worker are started when a job returns.
+func (w *Worker) nextRunAtLocked() (time.Time, bool) {
+	var (
+		next  time.Time
+		found bool
+	)
+
+	now := w.clock.Now()
+
+	for _, e := range w.pending {
+		if _, registered := w.handlers[e.job.Handler]; !registered || !e.runAt.After(now) {
+			continue
+		}
+
+		if !found || e.runAt.Before(next) {
+			next, found = e.runAt, true
+		}
+	}
+
+	return next, found
+}
+
+// run runs a job, then schedules its retry or moves it to the dead jobs
+// when it failed.
+func (w *Worker) run(base context.Context, e *entry, h handler) {
+	defer w.wg.Done()
+
+	name := e.job.Handler
+
+	w.logger.Info("received job", slog.String("job", name))
+
+	// The job is no longer pending, an identical one can be performed.
+	if e.attempts == 0 {
+		if err := worker.ReleaseUnique(base, w.uniqueStore, e.job); err != nil {
+			w.logger.Error("unable to release unique job", slog.String("job", name), slog.Any("error", err))
+		}
+	}
+
+	attempt := e.attempts + 1
+
+	w.track(base, e, worker.StatusRunning, attempt, nil)
+
+	ctx := trace.ContextWithRemoteSpanContext(base, trace.SpanContextFromContext(e.ctx))
+
+	if requestID, ok := worker.RequestIDFromContext(e.ctx); ok {
+		ctx = worker.WithRequestID(ctx, requestID)
+	}
+
+	ctx = worker.WithJobInfo(ctx, worker.JobInfo{
+		Handler: name,
+		Attempt: attempt,
+		Final:   h.cfg.retryPolicy.Exhausted(attempt),
+	})
+
+	if h.cfg.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.timeout)
+		defer cancel()
+	}
+
+	err := h.h(ctx, e.job.Args)
+
+	switch {
+	case err == nil:
+		w.track(base, e, worker.StatusSucceeded, attempt, nil)
+	case h.cfg.retryPolicy.Exhausted(attempt) || worker.IsPermanent(err):
+		w.track(base, e, worker.StatusDead, attempt, err)
+	default:
+		w.track(base, e, worker.StatusFailed, attempt, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running--
+
+	defer w.notifyLocked()
+
+	if err == nil {
+		return
+	}
+
+	e.attempts++
+	e.lastErr = err
+
+	if h.cfg.retryPolicy.Exhausted(e.attempts) || worker.IsPermanent(err) {
+		w.logger.Error(
+			"job exhausted its attempts",
+			slog.String("job", name),
+			slog.Int("attempt", e.attempts),
+			slog.Any("error", err),
+		)
+
+		w.dead = append(w.dead, e)
+
+		return
+	}
+
+	delay := h.cfg.retryPolicy.Backoff(e.attempts)
+
+	w.logger.Info(
+		"retrying job",
+		slog.String("job", name),
+		slog.Int("attempt", e.attempts),
+		slog.Duration("delay", delay),
+		slog.Any("error", err),
+	)
+
+	e.runAt = w.clock.Now().Add(delay)
+	w.pending = append(w.pending, e)
+}
+
+// track records the status of a job.
+func (w *Worker) track(ctx context.Context, e *entry, status worker.Status, attempt int, jobErr error) {
+	if err := worker.TrackStatus(ctx, w.statusStore, e.job.ID, status, attempt, jobErr); err != nil {
+		w.logger.Error("unable to record job status", slog.String("job", e.job.Handler), slog.Any("error", err))
+	}
+}
+
+func (w *Worker) notifyLocked() {
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
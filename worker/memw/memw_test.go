@@ -0,0 +1,324 @@
+package memw_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/memw"
+	"github.com/purposeinplay/go-commons/worker/middleware"
+	"github.com/purposeinplay/go-commons/worker/status"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorker_Perform(t *testing.T) {
+	r := require.New(t)
+
+	w := memw.New(memw.Options{})
+
+	var got worker.Args
+
+	r.NoError(w.Register("perform", func(args worker.Args) error {
+		got = args
+		return nil
+	}))
+
+	r.NoError(w.Perform(worker.Job{
+		Handler: "perform",
+		Args:    worker.Args{"id": 1},
+	}))
+
+	r.Len(w.Enqueued("perform"), 1)
+
+	r.NoError(w.RunUntilIdle(context.Background()))
+
+	// The args are passed through JSON, like with a broker.
+	r.Equal(worker.Args{"id": float64(1)}, got)
+	r.Empty(w.Enqueued("perform"))
+}
+
+func TestWorker_PerformIn(t *testing.T) {
+	r := require.New(t)
+
+	clock := worker.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	w := memw.New(memw.Options{Clock: clock})
+
+	var hits int
+
+	r.NoError(w.Register("perform_in", func(worker.Args) error {
+		hits++
+		return nil
+	}))
+
+	r.NoError(w.PerformIn(worker.Job{Handler: "perform_in"}, time.Minute))
+	r.NoError(w.PerformAt(worker.Job{Handler: "perform_in"}, clock.Now().Add(time.Hour)))
+
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal(0, hits)
+
+	clock.Advance(time.Minute)
+
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal(1, hits)
+	r.Len(w.Enqueued("perform_in"), 1)
+
+	r.NoError(w.Drain(context.Background()))
+	r.Equal(2, hits)
+}
+
+func TestWorker_Retry(t *testing.T) {
+	r := require.New(t)
+
+	clock := worker.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	w := memw.New(memw.Options{Clock: clock})
+
+	var attempts int
+
+	errFailed := errors.New("failed")
+
+	r.NoError(w.RegisterHandler("retry", func(context.Context, worker.Args) error {
+		attempts++
+		return errFailed
+	}, memw.WithRetryPolicy(worker.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		Multiplier:     2,
+	})))
+
+	r.NoError(w.Perform(worker.Job{Handler: "retry"}))
+
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal(1, attempts)
+	r.Len(w.Enqueued("retry"), 1)
+
+	clock.Advance(time.Second)
+
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal(2, attempts)
+
+	clock.Advance(2 * time.Second)
+
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal(3, attempts)
+	r.Empty(w.Enqueued("retry"))
+
+	dead := w.Dead("retry")
+	r.Len(dead, 1)
+	r.Equal(3, dead[0].Attempts)
+	r.ErrorIs(dead[0].Err, errFailed)
+}
+
+func TestWorker_Panic(t *testing.T) {
+	r := require.New(t)
+
+	w := memw.New(memw.Options{RetryPolicy: worker.NoRetry()})
+
+	r.NoError(w.Register("panic", func(worker.Args) error {
+		panic("boom")
+	}))
+
+	r.NoError(w.Perform(worker.Job{Handler: "panic"}))
+
+	// The panic fails the job instead of crashing the worker.
+	r.NoError(w.RunUntilIdle(context.Background()))
+
+	dead := w.Dead("panic")
+	r.Len(dead, 1)
+
+	var panicErr *middleware.PanicError
+
+	r.ErrorAs(dead[0].Err, &panicErr)
+	r.Equal("boom", panicErr.Value)
+}
+
+func TestWorker_MaxConcurrency(t *testing.T) {
+	r := require.New(t)
+
+	w := memw.New(memw.Options{MaxConcurrency: 2})
+
+	var (
+		running, maxRunning int32
+		wg                  sync.WaitGroup
+	)
+
+	wg.Add(6)
+
+	r.NoError(w.Register("concurrent", func(worker.Args) error {
+		defer wg.Done()
+
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r.NoError(w.Start(ctx))
+
+	for i := 0; i < 6; i++ {
+		r.NoError(w.Perform(worker.Job{Handler: "concurrent"}))
+	}
+
+	wg.Wait()
+
+	r.NoError(w.Stop())
+	r.LessOrEqual(atomic.LoadInt32(&maxRunning), int32(2))
+	r.ErrorIs(w.Perform(worker.Job{Handler: "concurrent"}), memw.ErrStopped)
+}
+
+func TestWorker_Context(t *testing.T) {
+	r := require.New(t)
+
+	w := memw.New(memw.Options{JobTimeout: time.Minute})
+
+	var (
+		requestID   string
+		hasDeadline bool
+	)
+
+	r.NoError(w.RegisterContext("context", func(ctx context.Context, _ worker.Args) error {
+		requestID, _ = worker.RequestIDFromContext(ctx)
+		_, hasDeadline = ctx.Deadline()
+
+		return nil
+	}))
+
+	ctx := worker.WithRequestID(context.Background(), "request-id")
+
+	r.NoError(w.PerformContext(ctx, worker.Job{Handler: "context"}))
+	r.NoError(w.RunUntilIdle(context.Background()))
+
+	r.Equal("request-id", requestID)
+	r.True(hasDeadline)
+}
+
+func TestWorker_Unique(t *testing.T) {
+	r := require.New(t)
+
+	w := memw.New(memw.Options{})
+
+	var hits int
+
+	r.NoError(w.Register("unique", func(worker.Args) error {
+		hits++
+		return nil
+	}))
+
+	job := worker.Job{Handler: "unique", UniqueKey: "user:1"}
+
+	r.NoError(w.Perform(job))
+
+	// A duplicate is rejected while the job is pending.
+	r.ErrorIs(w.Perform(job), worker.ErrDuplicateJob)
+
+	// Or coalesced with the pending one.
+	coalesced := job
+	coalesced.OnDuplicate = worker.CoalesceDuplicate
+
+	r.NoError(w.Perform(coalesced))
+	r.Len(w.Enqueued("unique"), 1)
+
+	// The key is scoped to the handler.
+	r.NoError(w.Perform(worker.Job{Handler: "other", UniqueKey: "user:1"}))
+
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal(1, hits)
+
+	// Once the job ran, an identical one can be performed.
+	r.NoError(w.Perform(job))
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal(2, hits)
+}
+
+func TestWorker_Priority(t *testing.T) {
+	r := require.New(t)
+
+	w := memw.New(memw.Options{MaxConcurrency: 1})
+
+	var got []float64
+
+	r.NoError(w.Register("priority", func(args worker.Args) error {
+		got = append(got, args["priority"].(float64))
+		return nil
+	}))
+
+	for _, priority := range []uint8{0, 5, 1} {
+		r.NoError(w.Perform(worker.Job{
+			Handler:  "priority",
+			Args:     worker.Args{"priority": priority},
+			Priority: priority,
+		}))
+	}
+
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal([]float64{5, 1, 0}, got)
+}
+
+func TestWorker_Status(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	clock := worker.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	store := status.NewMemoryStore(clock)
+
+	w := memw.New(memw.Options{Clock: clock, StatusStore: store})
+
+	r.NoError(w.RegisterHandler("retry", func(context.Context, worker.Args) error {
+		return errors.New("failed")
+	}, memw.WithRetryPolicy(worker.RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Second,
+	})))
+
+	id, err := worker.Perform(ctx, w, worker.Job{Handler: "retry"})
+	r.NoError(err)
+	r.NotEmpty(id)
+
+	job, err := store.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(worker.StatusPending, job.Status)
+
+	r.NoError(w.RunUntilIdle(ctx))
+
+	job, err = store.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(worker.StatusFailed, job.Status)
+	r.Equal(1, job.Attempts)
+	r.Equal("failed", job.LastError)
+
+	clock.Advance(time.Second)
+
+	r.NoError(w.RunUntilIdle(ctx))
+
+	job, err = store.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(worker.StatusDead, job.Status)
+	r.Equal(2, job.Attempts)
+
+	// A job that can't be enqueued is not recorded.
+	r.NoError(w.Stop())
+
+	_, err = worker.Perform(ctx, w, worker.Job{ID: "stopped", Handler: "retry"})
+	r.ErrorIs(err, memw.ErrStopped)
+
+	_, err = store.Get(ctx, "stopped")
+	r.ErrorIs(err, worker.ErrJobNotFound)
+}
@@ -0,0 +1,38 @@
+package memw
+
+import (
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// HandlerOption configures a handler registered with RegisterHandler.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	retryPolicy worker.RetryPolicy
+	timeout     time.Duration
+	middleware  []worker.Middleware
+}
+
+// WithRetryPolicy overrides the retry policy of the worker for a handler.
+func WithRetryPolicy(policy worker.RetryPolicy) HandlerOption {
+	return func(c *handlerConfig) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithTimeout overrides the job timeout of the worker for a handler.
+func WithTimeout(timeout time.Duration) HandlerOption {
+	return func(c *handlerConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithMiddleware wraps a handler with mws, inside the middlewares of the
+// worker.
+func WithMiddleware(mws ...worker.Middleware) HandlerOption {
+	return func(c *handlerConfig) {
+		c.middleware = append(c.middleware, mws...)
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import "context"
+
+// Middleware wraps a ContextHandler, to run code around every job of the
+// handlers it is applied to.
+type Middleware func(ContextHandler) ContextHandler
+
+// Chain wraps h with mws. The first middleware is the outermost one, it
+// runs first and returns last.
+func Chain(h ContextHandler, mws ...Middleware) ContextHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+
+	return h
+}
+
+// JobInfo describes the job being run, the workers set it in the context
+// of the handler.
+type JobInfo struct {
+	// Handler is the name the handler was registered with.
+	Handler string
+	// Attempt is the number of the current attempt, starting at 1.
+	Attempt int
+	// Final tells whether the current attempt is the last one: if it
+	// fails, the job is dead.
+	Final bool
+}
+
+type jobInfoKey struct{}
+
+// WithJobInfo returns a copy of ctx carrying info.
+func WithJobInfo(ctx context.Context, info JobInfo) context.Context {
+	return context.WithValue(ctx, jobInfoKey{}, info)
+}
+
+// JobInfoFromContext returns the JobInfo set with WithJobInfo.
+func JobInfoFromContext(ctx context.Context) (JobInfo, bool) {
+	info, ok := ctx.Value(jobInfoKey{}).(JobInfo)
+
+	return info, ok
+}
@@ -0,0 +1,143 @@
+// Package middleware provides worker.Middleware to recover from panics,
+// log, trace, measure, time out and report the errors of jobs.
+//
+// The middlewares are applied to every handler of a worker with
+// Options.Middleware, or to a single handler with WithMiddleware.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// PanicError is returned by the handlers wrapped with Recover when they
+// panic.
+type PanicError struct {
+	// Value is the value the handler panicked with.
+	Value any
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value the handler panicked with, when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
+
+// Recover turns the panics of the handler into a *PanicError, so the job
+// is retried like a failed one instead of crashing the worker. amqpw, memw
+// and pgw wrap every handler with it.
+func Recover() worker.Middleware {
+	return func(next worker.ContextHandler) worker.ContextHandler {
+		return func(ctx context.Context, args worker.Args) (err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					err = &PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}()
+
+			return next(ctx, args)
+		}
+	}
+}
+
+// Timeout cancels the context of the jobs that run longer than d.
+func Timeout(d time.Duration) worker.Middleware {
+	return func(next worker.ContextHandler) worker.ContextHandler {
+		return func(ctx context.Context, args worker.Args) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			return next(ctx, args)
+		}
+	}
+}
+
+// Logging logs the outcome and duration of every job with logger, along
+// with its handler, attempt and request id.
+func Logging(logger *slog.Logger) worker.Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next worker.ContextHandler) worker.ContextHandler {
+		return func(ctx context.Context, args worker.Args) error {
+			start := time.Now()
+
+			err := next(ctx, args)
+
+			attrs := append(jobAttrs(ctx), slog.Duration("duration", time.Since(start)))
+
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "job failed", append(attrs, slog.Any("error", err))...)
+
+				return err
+			}
+
+			logger.LogAttrs(ctx, slog.LevelInfo, "job succeeded", attrs...)
+
+			return nil
+		}
+	}
+}
+
+// jobAttrs returns the log attributes describing the job of ctx.
+func jobAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+
+	if info, ok := worker.JobInfoFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("job", info.Handler), slog.Int("attempt", info.Attempt))
+	}
+
+	if requestID, ok := worker.RequestIDFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+
+	return attrs
+}
+
+// ErrorReporter is the interface that wraps the ReportError method, it is
+// implemented by the otel.ErrorReporter and used by the grpc servers.
+type ErrorReporter interface {
+	ReportError(ctx context.Context, err error)
+}
+
+// ErrorReporterFunc is an ErrorReporter function. It adapts the reporters
+// returning an error, like the sentry client:
+//
+//	middleware.ErrorReporterFunc(func(ctx context.Context, err error) {
+//		_ = client.ReportError(ctx, err)
+//	})
+type ErrorReporterFunc func(ctx context.Context, err error)
+
+// ReportError calls f(ctx, err).
+func (f ErrorReporterFunc) ReportError(ctx context.Context, err error) {
+	f(ctx, err)
+}
+
+// ReportErrors reports the errors of the failed jobs with reporter. The
+// context of the handler is given to the reporter, it carries the trace
+// context, request id and worker.JobInfo of the job.
+func ReportErrors(reporter ErrorReporter) worker.Middleware {
+	return func(next worker.ContextHandler) worker.ContextHandler {
+		return func(ctx context.Context, args worker.Args) error {
+			err := next(ctx, args)
+			if err != nil {
+				reporter.ReportError(ctx, err)
+			}
+
+			return err
+		}
+	}
+}
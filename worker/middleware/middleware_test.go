@@ -0,0 +1,168 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/memw"
+	"github.com/purposeinplay/go-commons/worker/middleware"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecover(t *testing.T) {
+	r := require.New(t)
+
+	var (
+		mu       sync.Mutex
+		reported []error
+	)
+
+	reporter := middleware.ErrorReporterFunc(func(_ context.Context, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		reported = append(reported, err)
+	})
+
+	w := memw.New(memw.Options{
+		RetryPolicy: worker.NoRetry(),
+		Middleware: []worker.Middleware{
+			middleware.ReportErrors(reporter),
+			middleware.Recover(),
+		},
+	})
+
+	r.NoError(w.Register("panic", func(worker.Args) error {
+		panic("boom")
+	}))
+
+	r.NoError(w.Perform(worker.Job{Handler: "panic"}))
+	r.NoError(w.RunUntilIdle(context.Background()))
+
+	dead := w.Dead("panic")
+	r.Len(dead, 1)
+
+	var panicErr *middleware.PanicError
+
+	r.ErrorAs(dead[0].Err, &panicErr)
+	r.Equal("boom", panicErr.Value)
+	r.NotEmpty(panicErr.Stack)
+
+	r.Len(reported, 1)
+	r.ErrorAs(reported[0], &panicErr)
+}
+
+func TestWithMiddleware(t *testing.T) {
+	r := require.New(t)
+
+	var calls []string
+
+	mw := func(name string) worker.Middleware {
+		return func(next worker.ContextHandler) worker.ContextHandler {
+			return func(ctx context.Context, args worker.Args) error {
+				calls = append(calls, name)
+				return next(ctx, args)
+			}
+		}
+	}
+
+	w := memw.New(memw.Options{Middleware: []worker.Middleware{mw("global")}})
+
+	r.NoError(w.RegisterHandler("job", func(ctx context.Context, _ worker.Args) error {
+		info, ok := worker.JobInfoFromContext(ctx)
+		r.True(ok)
+		r.Equal(worker.JobInfo{Handler: "job", Attempt: 1}, info)
+
+		return nil
+	}, memw.WithMiddleware(mw("handler"))))
+
+	r.NoError(w.RegisterContext("other", func(context.Context, worker.Args) error {
+		return nil
+	}))
+
+	r.NoError(w.Perform(worker.Job{Handler: "job"}))
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal([]string{"global", "handler"}, calls)
+
+	r.NoError(w.Perform(worker.Job{Handler: "other"}))
+	r.NoError(w.RunUntilIdle(context.Background()))
+	r.Equal([]string{"global", "handler", "global"}, calls)
+}
+
+func TestTracing(t *testing.T) {
+	r := require.New(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	w := memw.New(memw.Options{
+		RetryPolicy: worker.NoRetry(),
+		Middleware:  []worker.Middleware{middleware.Tracing(provider)},
+	})
+
+	r.NoError(w.Register("trace", func(worker.Args) error {
+		return errors.New("failed")
+	}))
+
+	r.NoError(w.Perform(worker.Job{Handler: "trace"}))
+	r.NoError(w.RunUntilIdle(context.Background()))
+
+	spans := exporter.GetSpans()
+	r.Len(spans, 1)
+	r.Equal("trace", spans[0].Name)
+	r.Equal(codes.Error, spans[0].Status.Code)
+	r.Contains(spans[0].Attributes, middleware.JobKey.String("trace"))
+	r.Contains(spans[0].Attributes, middleware.AttemptKey.Int(1))
+}
+
+func TestMetrics(t *testing.T) {
+	r := require.New(t)
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	metrics, err := middleware.Metrics(provider)
+	r.NoError(err)
+
+	w := memw.New(memw.Options{
+		RetryPolicy: worker.NoRetry(),
+		Middleware:  []worker.Middleware{metrics},
+	})
+
+	r.NoError(w.Register("ok", func(worker.Args) error { return nil }))
+	r.NoError(w.Register("fail", func(worker.Args) error { return errors.New("failed") }))
+
+	r.NoError(w.Perform(worker.Job{Handler: "ok"}))
+	r.NoError(w.Perform(worker.Job{Handler: "ok"}))
+	r.NoError(w.Perform(worker.Job{Handler: "fail"}))
+	r.NoError(w.RunUntilIdle(context.Background()))
+
+	var rm metricdata.ResourceMetrics
+
+	r.NoError(reader.Collect(context.Background(), &rm))
+
+	counts := map[string]int64{}
+
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != "worker.jobs" {
+			continue
+		}
+
+		for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			job, _ := dp.Attributes.Value(middleware.JobKey)
+			outcome, _ := dp.Attributes.Value(middleware.OutcomeKey)
+
+			counts[job.AsString()+" "+outcome.AsString()] = dp.Value
+		}
+	}
+
+	r.Equal(map[string]int64{"ok success": 2, "fail failure": 1}, counts)
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer and meter of the
+// middlewares.
+const instrumentationName = "github.com/purposeinplay/go-commons/worker/middleware"
+
+// Attribute keys of the spans and metrics.
+const (
+	JobKey     = attribute.Key("worker.job")
+	AttemptKey = attribute.Key("worker.attempt")
+	OutcomeKey = attribute.Key("worker.outcome")
+)
+
+// Tracing starts a consumer span named after the handler for every job,
+// child of the trace context the job was performed with. A nil provider
+// means the global one.
+func Tracing(provider trace.TracerProvider) worker.Middleware {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	tracer := provider.Tracer(instrumentationName)
+
+	return func(next worker.ContextHandler) worker.ContextHandler {
+		return func(ctx context.Context, args worker.Args) error {
+			name := "job"
+
+			var attrs []attribute.KeyValue
+
+			if info, ok := worker.JobInfoFromContext(ctx); ok {
+				name = info.Handler
+				attrs = append(attrs, JobKey.String(info.Handler), AttemptKey.Int(info.Attempt))
+			}
+
+			ctx, span := tracer.Start(
+				ctx,
+				name,
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			err := next(ctx, args)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return err
+		}
+	}
+}
+
+// Metrics records, for every job, the worker.jobs counter and the
+// worker.job.duration histogram, by handler and outcome, and the
+// worker.jobs.running gauge. A nil provider means the global one.
+func Metrics(provider metric.MeterProvider) (worker.Middleware, error) {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+
+	meter := provider.Meter(instrumentationName)
+
+	jobs, err := meter.Int64Counter(
+		"worker.jobs",
+		metric.WithDescription("Number of jobs run."),
+		metric.WithUnit("{job}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create jobs counter: %w", err)
+	}
+
+	duration, err := meter.Float64Histogram(
+		"worker.job.duration",
+		metric.WithDescription("Duration of the jobs."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create duration histogram: %w", err)
+	}
+
+	running, err := meter.Int64UpDownCounter(
+		"worker.jobs.running",
+		metric.WithDescription("Number of jobs running."),
+		metric.WithUnit("{job}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create running counter: %w", err)
+	}
+
+	return func(next worker.ContextHandler) worker.ContextHandler {
+		return func(ctx context.Context, args worker.Args) error {
+			var job attribute.KeyValue
+
+			if info, ok := worker.JobInfoFromContext(ctx); ok {
+				job = JobKey.String(info.Handler)
+			} else {
+				job = JobKey.String("")
+			}
+
+			running.Add(ctx, 1, metric.WithAttributes(job))
+			defer running.Add(ctx, -1, metric.WithAttributes(job))
+
+			start := time.Now()
+
+			err := next(ctx, args)
+
+			outcome := OutcomeKey.String("success")
+			if err != nil {
+				outcome = OutcomeKey.String("failure")
+			}
+
+			attrs := metric.WithAttributes(job, outcome)
+
+			jobs.Add(ctx, 1, attrs)
+			duration.Record(ctx, time.Since(start).Seconds(), attrs)
+
+			return err
+		}
+	}, nil
+}
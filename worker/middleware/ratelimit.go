@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"golang.org/x/time/rate"
+)
+
+// RateLimit throttles the jobs to perSecond jobs per second, with bursts of
+// up to burst jobs. A job waits for its turn before running, holding its
+// worker, and fails when its context is done first.
+//
+// The limit is shared by the handlers wrapped with the returned
+// middleware: apply it with WithMiddleware to limit a single handler, for
+// example one calling a fragile third-party API. The limit is per process.
+func RateLimit(perSecond float64, burst int) worker.Middleware {
+	limiter := rate.NewLimiter(rate.Limit(perSecond), burst)
+
+	return func(next worker.ContextHandler) worker.ContextHandler {
+		return func(ctx context.Context, args worker.Args) error {
+			if err := limiter.Wait(ctx); err != nil {
+				return fmt.Errorf("rate limit: %w", err)
+			}
+
+			return next(ctx, args)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	r := require.New(t)
+
+	var runs int
+
+	h := worker.Chain(func(context.Context, worker.Args) error {
+		runs++
+		return nil
+	}, middleware.RateLimit(20, 1))
+
+	start := time.Now()
+
+	for range 3 {
+		r.NoError(h(context.Background(), worker.Args{}))
+	}
+
+	// The first job runs right away, the next ones every 50ms.
+	r.GreaterOrEqual(time.Since(start), 90*time.Millisecond)
+	r.Equal(3, runs)
+
+	// A job whose context is done while waiting fails.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r.ErrorIs(h(ctx, worker.Args{}), context.Canceled)
+	r.Equal(3, runs)
+}
@@ -0,0 +1,35 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	r := require.New(t)
+
+	var calls []string
+
+	mw := func(name string) worker.Middleware {
+		return func(next worker.ContextHandler) worker.ContextHandler {
+			return func(ctx context.Context, args worker.Args) error {
+				calls = append(calls, name+" before")
+				err := next(ctx, args)
+				calls = append(calls, name+" after")
+
+				return err
+			}
+		}
+	}
+
+	h := worker.Chain(func(context.Context, worker.Args) error {
+		calls = append(calls, "handler")
+		return nil
+	}, mw("outer"), mw("inner"))
+
+	r.NoError(h(context.Background(), worker.Args{}))
+	r.Equal([]string{"outer before", "inner before", "handler", "inner after", "outer after"}, calls)
+}
@@ -0,0 +1,330 @@
+// Package pgw implements worker.Worker on top of a PostgreSQL jobs table.
+//
+// Jobs are claimed with FOR UPDATE SKIP LOCKED, so many processes can
+// consume the same table. A claimed job is leased to its process, the lease
+// is extended while the job runs and a job whose lease expired, because its
+// process died, is claimed again.
+package pgw
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/purposeinplay/go-commons/rand"
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/middleware"
+)
+
+// Options are used to configure the PostgreSQL worker adapter.
+type Options struct {
+	// DB is the database holding the jobs table, opened with a PostgreSQL
+	// driver.
+	DB *sql.DB
+
+	// Logger is a logger interface to write the worker logs.
+	Logger *slog.Logger
+
+	// Name is used to identify the process holding the lease of a job.
+	// Defaults to the host name followed by a random suffix.
+	Name string
+
+	// Table is the name of the jobs table. Defaults to DefaultTable.
+	Table string
+
+	// MaxConcurrency restricts the amount of workers in parallel.
+	MaxConcurrency int
+
+	// PollInterval is how often the table is polled for due jobs.
+	// Defaults to 1s.
+	PollInterval time.Duration
+
+	// LeaseDuration is how long a claimed job is reserved to this process
+	// without heartbeat. The lease is extended every third of it while the
+	// job runs. Defaults to 30s.
+	LeaseDuration time.Duration
+
+	// RetryPolicy configures how the failed jobs are retried, it can be
+	// overridden per handler with WithRetryPolicy. Defaults to
+	// worker.DefaultRetryPolicy.
+	RetryPolicy worker.RetryPolicy
+
+	// JobTimeout cancels the context of a job that runs longer, it can be
+	// overridden per handler with WithTimeout. Zero means no timeout.
+	JobTimeout time.Duration
+
+	// Middleware wraps every handler, outside the middlewares given with
+	// WithMiddleware. The first middleware is the outermost one. The
+	// handlers are always wrapped with middleware.Recover first, so a
+	// panicking job fails and is retried instead of crashing the worker.
+	Middleware []worker.Middleware
+
+	// UniqueStore reserves the keys of the unique jobs, see
+	// worker.Job.UniqueKey. It must be shared by the processes performing
+	// jobs, unique.PGStore keeps the keys in the same database.
+	UniqueStore worker.UniqueStore
+
+	// StatusStore records the state of the jobs, see the status package.
+	// Defaults to nil, the state is not recorded. The state of a job
+	// enqueued in a transaction that is rolled back stays pending.
+	StatusStore worker.StatusStore
+}
+
+// ErrInvalidDB is returned when the DB opt is not defined.
+var ErrInvalidDB = errors.New("invalid db")
+
+// Ensures Adapter implements the Worker interface.
+var _ worker.ContextWorker = &Adapter{}
+
+// Adapter runs the jobs stored in a PostgreSQL table.
+type Adapter struct {
+	db             *sql.DB
+	logger         *slog.Logger
+	name           string
+	table          string
+	maxConcurrency int
+	pollInterval   time.Duration
+	leaseDuration  time.Duration
+	retryPolicy    worker.RetryPolicy
+	jobTimeout     time.Duration
+	middleware     []worker.Middleware
+	uniqueStore    worker.UniqueStore
+	statusStore    worker.StatusStore
+
+	// mu guards handlers.
+	mu       sync.Mutex
+	handlers map[string]handler
+
+	// sem restricts the running jobs to maxConcurrency.
+	sem chan struct{}
+	// stop is closed by Stop.
+	stop     chan struct{}
+	stopOnce sync.Once
+	// wg tracks the running jobs and the poller.
+	wg sync.WaitGroup
+}
+
+type handler struct {
+	h   worker.ContextHandler
+	cfg handlerConfig
+}
+
+// New creates a new PostgreSQL adapter.
+func New(opts Options) (*Adapter, error) {
+	if opts.DB == nil {
+		return nil, ErrInvalidDB
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	if opts.Name == "" {
+		host, _ := os.Hostname()
+		opts.Name = fmt.Sprintf("%s_%s", host, rand.String(10))
+	}
+
+	if opts.Table == "" {
+		opts.Table = DefaultTable
+	}
+
+	if opts.MaxConcurrency == 0 {
+		opts.MaxConcurrency = 25
+	}
+
+	if opts.PollInterval == 0 {
+		opts.PollInterval = time.Second
+	}
+
+	if opts.LeaseDuration == 0 {
+		opts.LeaseDuration = 30 * time.Second
+	}
+
+	if opts.RetryPolicy.MaxAttempts == 0 {
+		opts.RetryPolicy = worker.DefaultRetryPolicy()
+	}
+
+	return &Adapter{
+		db:             opts.DB,
+		logger:         opts.Logger,
+		name:           opts.Name,
+		table:          opts.Table,
+		maxConcurrency: opts.MaxConcurrency,
+		pollInterval:   opts.PollInterval,
+		leaseDuration:  opts.LeaseDuration,
+		retryPolicy:    opts.RetryPolicy,
+		jobTimeout:     opts.JobTimeout,
+		middleware:     opts.Middleware,
+		uniqueStore:    opts.UniqueStore,
+		statusStore:    opts.StatusStore,
+		handlers:       make(map[string]handler),
+		sem:            make(chan struct{}, opts.MaxConcurrency),
+		stop:           make(chan struct{}),
+	}, nil
+}
+
+// Start polls the jobs table until ctx is done or Stop is called. The
+// context of the jobs derives from ctx.
+func (a *Adapter) Start(ctx context.Context) error {
+	a.wg.Add(1)
+
+	go func() {
+		defer a.wg.Done()
+
+		a.poll(ctx)
+	}()
+
+	return nil
+}
+
+// Stop stops claiming jobs and waits for the running ones to return.
+func (a *Adapter) Stop() error {
+	a.logger.Info("stopping PostgreSQL worker")
+
+	a.stopOnce.Do(func() {
+		close(a.stop)
+	})
+
+	a.wg.Wait()
+
+	return nil
+}
+
+// Execer executes a statement, it is implemented by *sql.DB, *sql.Tx and
+// *sql.Conn.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Perform enqueues a new job.
+func (a *Adapter) Perform(job worker.Job) error {
+	return a.PerformContext(context.Background(), job)
+}
+
+// PerformContext enqueues a new job. The trace context and request id of
+// ctx are stored with the job and restored in the context of the handler.
+func (a *Adapter) PerformContext(ctx context.Context, job worker.Job) error {
+	return a.PerformTx(ctx, a.db, job)
+}
+
+// PerformIn performs a job delayed by the given duration.
+func (a *Adapter) PerformIn(job worker.Job, d time.Duration) error {
+	return a.PerformAtTx(context.Background(), a.db, job, time.Now().Add(d))
+}
+
+// PerformAt performs a job at the given time.
+func (a *Adapter) PerformAt(job worker.Job, t time.Time) error {
+	return a.PerformAtTx(context.Background(), a.db, job, t)
+}
+
+// PerformTx enqueues a new job with tx. When tx is a transaction, the job
+// is only enqueued if the transaction commits, atomically with the data
+// written in it.
+func (a *Adapter) PerformTx(ctx context.Context, tx Execer, job worker.Job) error {
+	return a.PerformAtTx(ctx, tx, job, time.Now())
+}
+
+// PerformAtTx enqueues with tx a job performed at the given time.
+//
+// A unique job performed while an identical one is pending is rejected
+// with worker.ErrDuplicateJob or coalesced, see worker.Job.UniqueKey. The
+// unique key is reserved in the UniqueStore even if tx is rolled back, until
+// it expires.
+func (a *Adapter) PerformAtTx(ctx context.Context, tx Execer, job worker.Job, t time.Time) error {
+	if job.ID == "" {
+		job.ID = worker.NewJobID()
+	}
+
+	a.logger.Info("enqueuing job", slog.Any("job", job))
+
+	headers, err := encodeHeaders(ctx, job)
+	if err != nil {
+		return fmt.Errorf("error enqueuing job: %w", err)
+	}
+
+	enqueue, err := worker.AcquireUnique(ctx, a.uniqueStore, job)
+	if err != nil || !enqueue {
+		return err
+	}
+
+	// Record the job before it can run.
+	if err := worker.TrackEnqueued(ctx, a.statusStore, job); err != nil {
+		a.logger.Error("unable to record job", slog.Any("job", job), slog.Any("error", err))
+	}
+
+	args := job.Args
+	if args == nil {
+		args = worker.Args{}
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`INSERT INTO %s (handler, queue, priority, args, headers, run_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+			a.table,
+		),
+		job.Handler,
+		job.Queue,
+		int16(job.Priority),
+		args.String(),
+		string(headers),
+		t,
+	)
+	if err != nil {
+		a.logger.Error("error enqueuing job", slog.Any("job", job))
+
+		_ = worker.ReleaseUnique(ctx, a.uniqueStore, job)
+
+		// The job won't run and the caller gets the error, so it is not
+		// left for status.Inspector.Retry to perform again.
+		_ = worker.Untrack(ctx, a.statusStore, job.ID)
+
+		return fmt.Errorf("error enqueuing job: %w", err)
+	}
+
+	return nil
+}
+
+// Register a Handler.
+func (a *Adapter) Register(name string, h worker.Handler) error {
+	return a.RegisterHandler(name, worker.AdaptHandler(h))
+}
+
+// RegisterContext registers a ContextHandler.
+func (a *Adapter) RegisterContext(name string, h worker.ContextHandler) error {
+	return a.RegisterHandler(name, h)
+}
+
+// RegisterHandler registers a ContextHandler configured with opts.
+//
+// A job whose handler fails is retried with the backoff of the retry
+// policy. Once its attempts are exhausted, the job is kept in the table
+// with the dead status and its last error.
+func (a *Adapter) RegisterHandler(name string, h worker.ContextHandler, opts ...HandlerOption) error {
+	a.logger.Info("register job", slog.String("job", name))
+
+	cfg := handlerConfig{
+		retryPolicy: a.retryPolicy,
+		timeout:     a.jobTimeout,
+		middleware:  slices.Clone(a.middleware),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	h = worker.Chain(h, slices.Insert(cfg.middleware, 0, middleware.Recover())...)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.handlers[name] = handler{h: h, cfg: cfg}
+
+	return nil
+}
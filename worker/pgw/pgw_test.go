@@ -0,0 +1,235 @@
+package pgw
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/internal/pgtest"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdapter returns a started adapter on a fresh jobs table.
+func newAdapter(t *testing.T, opts Options) *Adapter {
+	t.Helper()
+
+	opts.DB = pgtest.DB(t)
+	opts.Table = pgtest.Table(t, "jobs")
+
+	if opts.PollInterval == 0 {
+		opts.PollInterval = 10 * time.Millisecond
+	}
+
+	a, err := New(opts)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, a.Migrate(ctx))
+
+	return a
+}
+
+func (a *Adapter) count(t *testing.T, status string) int {
+	t.Helper()
+
+	var n int
+
+	err := pgtest.DB(t).QueryRow(
+		"SELECT count(*) FROM "+a.table+" WHERE status = $1",
+		status,
+	).Scan(&n)
+	require.NoError(t, err)
+
+	return n
+}
+
+func Test_Perform(t *testing.T) {
+	r := require.New(t)
+
+	a := newAdapter(t, Options{})
+
+	got := make(chan worker.Args, 1)
+
+	r.NoError(a.Register("perform", func(args worker.Args) error {
+		got <- args
+		return nil
+	}))
+
+	r.NoError(a.Start(context.Background()))
+	defer a.Stop()
+
+	r.NoError(a.Perform(worker.Job{
+		Handler: "perform",
+		Args:    worker.Args{"id": "1"},
+	}))
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for job")
+	case args := <-got:
+		r.Equal("1", args["id"])
+	}
+
+	// Succeeded jobs are deleted.
+	r.Eventually(func() bool {
+		return a.count(t, "running") == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_PerformTx(t *testing.T) {
+	r := require.New(t)
+
+	a := newAdapter(t, Options{})
+
+	ctx := context.Background()
+
+	tx, err := pgtest.DB(t).BeginTx(ctx, nil)
+	r.NoError(err)
+
+	r.NoError(a.PerformTx(ctx, tx, worker.Job{Handler: "perform_tx"}))
+	r.NoError(tx.Rollback())
+
+	r.Equal(0, a.count(t, "pending"))
+
+	tx, err = pgtest.DB(t).BeginTx(ctx, nil)
+	r.NoError(err)
+
+	r.NoError(a.PerformTx(ctx, tx, worker.Job{Handler: "perform_tx"}))
+	r.NoError(tx.Commit())
+
+	r.Equal(1, a.count(t, "pending"))
+}
+
+func Test_PerformIn(t *testing.T) {
+	r := require.New(t)
+
+	a := newAdapter(t, Options{})
+
+	got := make(chan time.Time, 1)
+
+	r.NoError(a.Register("perform_in", func(worker.Args) error {
+		got <- time.Now()
+		return nil
+	}))
+
+	r.NoError(a.Start(context.Background()))
+	defer a.Stop()
+
+	start := time.Now()
+
+	r.NoError(a.PerformIn(worker.Job{Handler: "perform_in"}, 500*time.Millisecond))
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for job")
+	case ranAt := <-got:
+		r.GreaterOrEqual(ranAt.Sub(start), 500*time.Millisecond)
+	}
+}
+
+func Test_Retry(t *testing.T) {
+	r := require.New(t)
+
+	a := newAdapter(t, Options{})
+
+	var (
+		mu    sync.Mutex
+		tries int
+	)
+
+	r.NoError(a.RegisterHandler("retry", func(context.Context, worker.Args) error {
+		mu.Lock()
+		tries++
+		mu.Unlock()
+
+		return errors.New("failed")
+	}, WithRetryPolicy(worker.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		Multiplier:     2,
+	})))
+
+	r.NoError(a.Start(context.Background()))
+	defer a.Stop()
+
+	r.NoError(a.Perform(worker.Job{Handler: "retry"}))
+
+	r.Eventually(func() bool {
+		return a.count(t, "dead") == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var lastError string
+
+	r.NoError(pgtest.DB(t).QueryRow("SELECT last_error FROM " + a.table).Scan(&lastError))
+	r.Equal("failed", lastError)
+
+	mu.Lock()
+	r.Equal(3, tries)
+	mu.Unlock()
+}
+
+func Test_LeaseExpired(t *testing.T) {
+	r := require.New(t)
+
+	a := newAdapter(t, Options{LeaseDuration: time.Second})
+
+	// A job leased to a process that died.
+	_, err := pgtest.DB(t).Exec(
+		"INSERT INTO " + a.table + " (handler, status, attempts, locked_by, locked_until)" +
+			" VALUES ('lease', 'running', 1, 'dead-process', now() - interval '1 second')",
+	)
+	r.NoError(err)
+
+	got := make(chan struct{}, 1)
+
+	r.NoError(a.Register("lease", func(worker.Args) error {
+		got <- struct{}{}
+		return nil
+	}))
+
+	r.NoError(a.Start(context.Background()))
+	defer a.Stop()
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for job")
+	case <-got:
+	}
+}
+
+func Test_Priority(t *testing.T) {
+	r := require.New(t)
+
+	a := newAdapter(t, Options{MaxConcurrency: 1})
+
+	for _, priority := range []uint8{0, 5, 1} {
+		r.NoError(a.Perform(worker.Job{
+			Handler:  "priority",
+			Args:     worker.Args{"priority": priority},
+			Priority: priority,
+		}))
+	}
+
+	got := make(chan float64, 3)
+
+	r.NoError(a.Register("priority", func(args worker.Args) error {
+		got <- args["priority"].(float64)
+		return nil
+	}))
+
+	r.NoError(a.Start(context.Background()))
+	defer a.Stop()
+
+	for _, want := range []float64{5, 1, 0} {
+		select {
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for job")
+		case priority := <-got:
+			r.Equal(want, priority)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package pgw
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// errLeaseExpired is the error of the jobs whose lease expired more times
+// than their attempts allow, they likely crash their process.
+var errLeaseExpired = errors.New("lease expired")
+
+// claimedJob is a job leased to this process.
+type claimedJob struct {
+	id      int64
+	job     worker.Job
+	headers []byte
+	// attempts is the number of times the job was claimed, including this
+	// one.
+	attempts int
+}
+
+// poll claims and runs the due jobs until ctx is done or Stop is called.
+func (a *Adapter) poll(ctx context.Context) {
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed := a.claimAndRun(ctx)
+
+		// Poll again right away when jobs were claimed, more may be due.
+		if claimed > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-a.stop:
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimAndRun claims as many due jobs as there are free workers and runs
+// them, it returns the number of claimed jobs.
+func (a *Adapter) claimAndRun(ctx context.Context) int {
+	a.mu.Lock()
+	handlers := make(map[string]handler, len(a.handlers))
+
+	for name, h := range a.handlers {
+		handlers[name] = h
+	}
+	a.mu.Unlock()
+
+	var claimed int
+
+	for name, h := range handlers {
+		// Only the poller takes slots, so they can only be freed meanwhile.
+		free := cap(a.sem) - len(a.sem)
+		if free == 0 {
+			break
+		}
+
+		jobs, err := a.claim(ctx, name, free)
+		if err != nil {
+			a.logger.Error("unable to claim jobs", slog.String("job", name), slog.Any("error", err))
+			continue
+		}
+
+		for _, j := range jobs {
+			a.sem <- struct{}{}
+
+			a.wg.Add(1)
+
+			go func(j claimedJob) {
+				defer a.wg.Done()
+				defer func() { <-a.sem }()
+
+				a.run(ctx, j, h)
+			}(j)
+		}
+
+		claimed += len(jobs)
+	}
+
+	return claimed
+}
+
+// claim leases up to limit due jobs of handler, the pending ones and the
+// running ones whose lease expired, by priority then by time.
+func (a *Adapter) claim(ctx context.Context, name string, limit int) ([]claimedJob, error) {
+	rows, err := a.db.QueryContext(
+		ctx,
+		fmt.Sprintf(`
+UPDATE %[1]s
+SET status = 'running',
+	locked_by = $1,
+	locked_until = now() + ($2::float8 * interval '1 second'),
+	attempts = attempts + 1,
+	updated_at = now()
+WHERE id IN (
+	SELECT id FROM %[1]s
+	WHERE handler = $3
+	AND (
+		(status = 'pending' AND run_at <= now())
+		OR (status = 'running' AND locked_until < now())
+	)
+	ORDER BY priority DESC, run_at, id
+	LIMIT $4
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, queue, priority, args, headers, attempts`, a.table),
+		a.name,
+		a.leaseDuration.Seconds(),
+		name,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim jobs: %w", err)
+	}
+
+	defer rows.Close()
+
+	var jobs []claimedJob
+
+	for rows.Next() {
+		var (
+			j    = claimedJob{job: worker.Job{Handler: name}}
+			args []byte
+		)
+
+		if err := rows.Scan(&j.id, &j.job.Queue, &j.job.Priority, &args, &j.headers, &j.attempts); err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+
+		j.job.Args = worker.Args{}
+
+		if err := json.Unmarshal(args, &j.job.Args); err != nil {
+			j.job.Args = nil
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// run runs a claimed job while extending its lease, then deletes it or
+// schedules its retry.
+func (a *Adapter) run(base context.Context, j claimedJob, h handler) {
+	name := j.job.Handler
+
+	a.logger.Info("received job", slog.String("job", name), slog.Int64("id", j.id))
+
+	// The outcome of the job is stored even when the worker is stopping.
+	storeCtx := context.WithoutCancel(base)
+
+	// The job is no longer pending, an identical one can be performed.
+	if uniqueKey := headerValue(j.headers, uniqueKeyHeader); uniqueKey != "" && j.attempts == 1 {
+		job := worker.Job{Handler: name, UniqueKey: uniqueKey}
+
+		if err := worker.ReleaseUnique(storeCtx, a.uniqueStore, job); err != nil {
+			a.logger.Error("unable to release unique job", slog.String("job", name), slog.Any("error", err))
+		}
+	}
+
+	if j.job.Args == nil {
+		a.logger.Info("unable to retrieve job", slog.String("job", name), slog.Int64("id", j.id))
+
+		a.bury(storeCtx, j, errors.New("invalid args"))
+
+		return
+	}
+
+	if j.attempts > h.cfg.retryPolicy.MaxAttempts {
+		a.bury(storeCtx, j, errLeaseExpired)
+
+		return
+	}
+
+	a.track(storeCtx, j, worker.StatusRunning, nil)
+
+	ctx, cancel := context.WithCancel(worker.WithJobInfo(
+		decodeContext(base, j.headers),
+		worker.JobInfo{
+			Handler: name,
+			Attempt: j.attempts,
+			Final:   h.cfg.retryPolicy.Exhausted(j.attempts),
+		},
+	))
+	defer cancel()
+
+	if h.cfg.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.timeout)
+		defer cancel()
+	}
+
+	heartbeatDone := make(chan struct{})
+	stopHeartbeat := make(chan struct{})
+
+	go func() {
+		defer close(heartbeatDone)
+
+		a.heartbeat(storeCtx, j, cancel, stopHeartbeat)
+	}()
+
+	err := h.h(ctx, j.job.Args)
+
+	close(stopHeartbeat)
+	<-heartbeatDone
+
+	if err == nil {
+		a.complete(storeCtx, j)
+		return
+	}
+
+	a.logger.Info("unable to process job", slog.String("job", name), slog.Any("error", err))
+
+	if h.cfg.retryPolicy.Exhausted(j.attempts) || worker.IsPermanent(err) {
+		a.bury(storeCtx, j, err)
+		return
+	}
+
+	a.retry(storeCtx, j, h.cfg.retryPolicy.Backoff(j.attempts), err)
+}
+
+// heartbeat extends the lease of the job until stop is closed. When the
+// lease is lost, the job is cancelled.
+func (a *Adapter) heartbeat(
+	ctx context.Context,
+	j claimedJob,
+	cancel context.CancelFunc,
+	stop <-chan struct{},
+) {
+	ticker := time.NewTicker(a.leaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		res, err := a.db.ExecContext(
+			ctx,
+			fmt.Sprintf(`
+UPDATE %s
+SET locked_until = now() + ($1::float8 * interval '1 second'), updated_at = now()
+WHERE id = $2 AND locked_by = $3 AND status = 'running'`, a.table),
+			a.leaseDuration.Seconds(),
+			j.id,
+			a.name,
+		)
+		if err != nil {
+			a.logger.Error("unable to extend job lease", slog.Int64("id", j.id), slog.Any("error", err))
+			continue
+		}
+
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			a.logger.Error("job lease lost", slog.String("job", j.job.Handler), slog.Int64("id", j.id))
+
+			cancel()
+
+			return
+		}
+	}
+}
+
+// complete deletes a succeeded job.
+func (a *Adapter) complete(ctx context.Context, j claimedJob) {
+	a.track(ctx, j, worker.StatusSucceeded, nil)
+
+	_, err := a.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND locked_by = $2`, a.table),
+		j.id,
+		a.name,
+	)
+	if err != nil {
+		a.logger.Error("unable to complete job", slog.Int64("id", j.id), slog.Any("error", err))
+	}
+}
+
+// retry releases a failed job, due again after delay.
+func (a *Adapter) retry(ctx context.Context, j claimedJob, delay time.Duration, jobErr error) {
+	a.logger.Info(
+		"retrying job",
+		slog.String("job", j.job.Handler),
+		slog.Int("attempt", j.attempts),
+		slog.Duration("delay", delay),
+		slog.Any("error", jobErr),
+	)
+
+	a.track(ctx, j, worker.StatusFailed, jobErr)
+
+	_, err := a.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`
+UPDATE %s
+SET status = 'pending',
+	run_at = now() + ($1::float8 * interval '1 second'),
+	locked_by = NULL,
+	locked_until = NULL,
+	last_error = $2,
+	updated_at = now()
+WHERE id = $3 AND locked_by = $4`, a.table),
+		delay.Seconds(),
+		jobErr.Error(),
+		j.id,
+		a.name,
+	)
+	if err != nil {
+		a.logger.Error("unable to retry job", slog.Int64("id", j.id), slog.Any("error", err))
+	}
+}
+
+// bury marks a job as dead, it is kept in the table with its last error.
+func (a *Adapter) bury(ctx context.Context, j claimedJob, jobErr error) {
+	a.logger.Error(
+		"job exhausted its attempts",
+		slog.String("job", j.job.Handler),
+		slog.Int("attempt", j.attempts),
+		slog.Any("error", jobErr),
+	)
+
+	a.track(ctx, j, worker.StatusDead, jobErr)
+
+	_, err := a.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`
+UPDATE %s
+SET status = 'dead',
+	locked_by = NULL,
+	locked_until = NULL,
+	last_error = $1,
+	updated_at = now()
+WHERE id = $2 AND locked_by = $3`, a.table),
+		jobErr.Error(),
+		j.id,
+		a.name,
+	)
+	if err != nil {
+		a.logger.Error("unable to bury job", slog.Int64("id", j.id), slog.Any("error", err))
+	}
+}
+
+// track records the status of a claimed job.
+func (a *Adapter) track(ctx context.Context, j claimedJob, status worker.Status, jobErr error) {
+	id := headerValue(j.headers, jobIDHeader)
+
+	if err := worker.TrackStatus(ctx, a.statusStore, id, status, j.attempts, jobErr); err != nil {
+		a.logger.Error("unable to record job status", slog.String("job", id), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,69 @@
+package pgw
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/purposeinplay/go-commons/worker"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	// uniqueKeyHeader holds the unique key of a job.
+	uniqueKeyHeader = "x-unique-key"
+
+	// jobIDHeader holds the ID of a job.
+	jobIDHeader = "x-job-id"
+)
+
+// encodeHeaders returns the headers carrying the trace context, with the
+// global propagator, the request id of ctx and the unique key and ID of
+// job.
+func encodeHeaders(ctx context.Context, job worker.Job) ([]byte, error) {
+	headers := propagation.MapCarrier{}
+
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+
+	if requestID, ok := worker.RequestIDFromContext(ctx); ok {
+		headers[worker.RequestIDHeader] = requestID
+	}
+
+	if job.UniqueKey != "" {
+		headers[uniqueKeyHeader] = job.UniqueKey
+	}
+
+	headers[jobIDHeader] = job.ID
+
+	return json.Marshal(headers)
+}
+
+// decodeContext restores in ctx the trace context and the request id
+// carried by the headers.
+func decodeContext(ctx context.Context, b []byte) context.Context {
+	headers := propagation.MapCarrier{}
+
+	if err := json.Unmarshal(b, &headers); err != nil {
+		return ctx
+	}
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headers)
+
+	if requestID := headers[worker.RequestIDHeader]; requestID != "" {
+		ctx = worker.WithRequestID(ctx, requestID)
+	}
+
+	return ctx
+}
+
+// headerValue returns the value of a header.
+func headerValue(b []byte, key string) string {
+	headers := propagation.MapCarrier{}
+
+	if err := json.Unmarshal(b, &headers); err != nil {
+		return ""
+	}
+
+	return headers[key]
+}
@@ -0,0 +1,47 @@
+package pgw
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultTable is the name of the jobs table.
+const DefaultTable = "worker_jobs"
+
+// Schema returns the statements creating the jobs table, for the services
+// running their own migrations. Migrate runs them.
+func Schema(table string) string {
+	return fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %[1]s (
+	id           BIGSERIAL PRIMARY KEY,
+	handler      TEXT NOT NULL,
+	queue        TEXT NOT NULL DEFAULT '',
+	priority     SMALLINT NOT NULL DEFAULT 0,
+	args         JSONB NOT NULL DEFAULT '{}',
+	headers      JSONB NOT NULL DEFAULT '{}',
+	status       TEXT NOT NULL DEFAULT 'pending',
+	run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+	attempts     INT NOT NULL DEFAULT 0,
+	locked_by    TEXT,
+	locked_until TIMESTAMPTZ,
+	last_error   TEXT,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- The tables created before the priorities.
+ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS %[1]s_dequeue_idx
+	ON %[1]s (handler, status, run_at);
+`, table)
+}
+
+// Migrate creates the jobs table if it does not exist.
+func (a *Adapter) Migrate(ctx context.Context) error {
+	if _, err := a.db.ExecContext(ctx, Schema(a.table)); err != nil {
+		return fmt.Errorf("create jobs table: %w", err)
+	}
+
+	return nil
+}
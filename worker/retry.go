@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// RetryPolicy configures how the failed jobs are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a job is run, including the
+	// first attempt. A job that failed MaxAttempts times is dead.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+
+	// Multiplier grows the delay after every retry.
+	Multiplier float64
+}
+
+// DefaultRetryPolicy runs a job up to 5 times, waiting 1s, 2s, 4s and 8s
+// between the attempts.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Minute,
+		Multiplier:     2,
+	}
+}
+
+// NoRetry runs a job only once.
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// Exhausted tells whether a job that failed its attempt-th attempt, starting
+// at 1, should not be retried.
+func (p RetryPolicy) Exhausted(attempt int) bool {
+	return attempt >= p.MaxAttempts
+}
+
+// Permanent marks err as the error of a job that would fail on every
+// attempt, e.g. an invalid payload: the workers don't retry the job, it is
+// dead right away. It returns nil when err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsPermanent tells whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+
+	return errors.As(err, &permanent)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Backoff returns the delay before retrying a job that failed its
+// attempt-th attempt, starting at 1.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+
+	return time.Duration(backoff)
+}
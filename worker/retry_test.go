@@ -0,0 +1,46 @@
+package worker_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	r := require.New(t)
+
+	p := worker.RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Second,
+		MaxBackoff:     3 * time.Second,
+		Multiplier:     2,
+	}
+
+	r.Equal(time.Second, p.Backoff(1))
+	r.Equal(2*time.Second, p.Backoff(2))
+	r.Equal(3*time.Second, p.Backoff(3))
+
+	r.False(p.Exhausted(3))
+	r.True(p.Exhausted(4))
+
+	r.True(worker.NoRetry().Exhausted(1))
+}
+
+func TestPermanent(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(worker.Permanent(nil))
+
+	errBad := errors.New("bad input")
+
+	err := fmt.Errorf("process: %w", worker.Permanent(errBad))
+	r.True(worker.IsPermanent(err))
+	r.ErrorIs(err, errBad)
+	r.Equal("process: bad input", err.Error())
+
+	r.False(worker.IsPermanent(errBad))
+}
@@ -0,0 +1,166 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Status is the state of a job.
+type Status string
+
+const (
+	// StatusPending is the status of the jobs waiting to run, including
+	// the scheduled ones and the ones waiting for a retry.
+	StatusPending Status = "pending"
+	// StatusRunning is the status of the jobs whose handler is running.
+	StatusRunning Status = "running"
+	// StatusSucceeded is the status of the jobs whose handler succeeded.
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed is the status of the jobs whose handler failed, that
+	// will be retried.
+	StatusFailed Status = "failed"
+	// StatusDead is the status of the jobs that exhausted their attempts.
+	StatusDead Status = "dead"
+)
+
+// ErrJobNotFound is returned when a StatusStore has no job with an ID.
+var ErrJobNotFound = errors.New("job not found")
+
+// JobStatus is the state of a job recorded in a StatusStore.
+type JobStatus struct {
+	ID      string `json:"id"`
+	Handler string `json:"handler"`
+	Queue   string `json:"queue,omitempty"`
+	Args    Args   `json:"args"`
+	Status  Status `json:"status"`
+	// Attempts is the number of times the job ran, or is running.
+	Attempts int `json:"attempts"`
+	// LastError is the error of the last failed attempt.
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Job returns the job to perform again.
+func (s JobStatus) Job() Job {
+	return Job{
+		ID:      s.ID,
+		Handler: s.Handler,
+		Queue:   s.Queue,
+		Args:    s.Args,
+	}
+}
+
+// StatusUpdate changes the state of a job.
+type StatusUpdate struct {
+	Status   Status
+	Attempts int
+	// LastError replaces the last error of the job when not empty.
+	LastError string
+}
+
+// StatusFilter selects the jobs listed by a StatusStore. The zero values
+// match all the jobs.
+type StatusFilter struct {
+	Status  Status
+	Handler string
+	// Limit bounds the number of jobs, the most recently updated first.
+	Limit int
+}
+
+// StatusStore records the state of the jobs. The workers given one update
+// it as their jobs are enqueued and run.
+type StatusStore interface {
+	// Create records a pending job, replacing the state of a job with the
+	// same ID.
+	Create(ctx context.Context, job Job) error
+
+	// Update changes the state of a job.
+	Update(ctx context.Context, id string, update StatusUpdate) error
+
+	// Get returns the state of a job, or ErrJobNotFound.
+	Get(ctx context.Context, id string) (JobStatus, error)
+
+	// List returns the state of the jobs matching filter, the most
+	// recently updated first.
+	List(ctx context.Context, filter StatusFilter) ([]JobStatus, error)
+
+	// Delete removes the state of a job, a job not found is ignored.
+	Delete(ctx context.Context, id string) error
+}
+
+// NewJobID returns a new job ID, a UUIDv7.
+func NewJobID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// Perform performs job on w and returns its ID, generated when job.ID is
+// empty. A unique job coalesced with a pending one is not recorded, its ID
+// is not found.
+func Perform(ctx context.Context, w Worker, job Job) (string, error) {
+	if job.ID == "" {
+		job.ID = NewJobID()
+	}
+
+	if err := PerformContext(ctx, w, job); err != nil {
+		return "", err
+	}
+
+	return job.ID, nil
+}
+
+// TrackEnqueued records job as pending in store, the workers call it once
+// the job is enqueued.
+func TrackEnqueued(ctx context.Context, store StatusStore, job Job) error {
+	if store == nil || job.ID == "" {
+		return nil
+	}
+
+	if err := store.Create(ctx, job); err != nil {
+		return fmt.Errorf("record job status: %w", err)
+	}
+
+	return nil
+}
+
+// Untrack removes the job id from store, the workers call it when a job
+// recorded by TrackEnqueued could not be enqueued: the caller gets the
+// error and may perform the job again.
+func Untrack(ctx context.Context, store StatusStore, id string) error {
+	if store == nil || id == "" {
+		return nil
+	}
+
+	if err := store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete job status: %w", err)
+	}
+
+	return nil
+}
+
+// TrackStatus records the status of the job id at its attempt in store,
+// with the error of the attempt.
+func TrackStatus(ctx context.Context, store StatusStore, id string, status Status, attempt int, jobErr error) error {
+	if store == nil || id == "" {
+		return nil
+	}
+
+	update := StatusUpdate{
+		Status:   status,
+		Attempts: attempt,
+	}
+
+	if jobErr != nil {
+		update.LastError = jobErr.Error()
+	}
+
+	if err := store.Update(ctx, id, update); err != nil {
+		return fmt.Errorf("record job status: %w", err)
+	}
+
+	return nil
+}
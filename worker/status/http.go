@@ -0,0 +1,95 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// Handler serves the inspector over HTTP, with JSON responses:
+//
+//	GET  /jobs?status=dead&handler=send_email&limit=50
+//	GET  /jobs/{id}
+//	POST /jobs/{id}/retry
+//
+// Mount it under a prefix with http.StripPrefix, behind the authentication
+// of the service.
+func Handler(inspector *Inspector) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		filter := worker.StatusFilter{
+			Status:  worker.Status(r.URL.Query().Get("status")),
+			Handler: r.URL.Query().Get("handler"),
+		}
+
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, errors.New("invalid limit"))
+				return
+			}
+
+			filter.Limit = n
+		}
+
+		jobs, err := inspector.List(r.Context(), filter)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if jobs == nil {
+			jobs = []worker.JobStatus{}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"jobs": jobs})
+	})
+
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		job, err := inspector.Get(r.Context(), r.PathValue("id"))
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, job)
+	})
+
+	mux.HandleFunc("POST /jobs/{id}/retry", func(w http.ResponseWriter, r *http.Request) {
+		if err := inspector.Retry(r.Context(), r.PathValue("id")); err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	return mux
+}
+
+// errorStatus returns the HTTP status of an inspector error.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, worker.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotDead):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,53 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// ErrNotDead is returned when a job that is not dead is retried.
+var ErrNotDead = errors.New("job not dead")
+
+// Inspector queries the jobs of a worker and retries the dead ones. The
+// worker must record the state of its jobs in the store.
+type Inspector struct {
+	store  worker.StatusStore
+	worker worker.Worker
+}
+
+// NewInspector returns an Inspector of the jobs of w recorded in store.
+func NewInspector(store worker.StatusStore, w worker.Worker) *Inspector {
+	return &Inspector{store: store, worker: w}
+}
+
+// Get returns the state of a job, or worker.ErrJobNotFound.
+func (i *Inspector) Get(ctx context.Context, id string) (worker.JobStatus, error) {
+	return i.store.Get(ctx, id)
+}
+
+// List returns the state of the jobs matching filter.
+func (i *Inspector) List(ctx context.Context, filter worker.StatusFilter) ([]worker.JobStatus, error) {
+	return i.store.List(ctx, filter)
+}
+
+// Retry performs a dead job again, with the same ID and a fresh count of
+// attempts. The dead copy kept by the worker, if any, is left as is.
+func (i *Inspector) Retry(ctx context.Context, id string) error {
+	job, err := i.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if job.Status != worker.StatusDead {
+		return fmt.Errorf("%w: %s is %s", ErrNotDead, id, job.Status)
+	}
+
+	if _, err := worker.Perform(ctx, i.worker, job.Job()); err != nil {
+		return fmt.Errorf("retry job: %w", err)
+	}
+
+	return nil
+}
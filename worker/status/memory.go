@@ -0,0 +1,136 @@
+// Package status records the state of the jobs in memory or on PostgreSQL,
+// and inspects them: Inspector queries the jobs and retries the dead ones,
+// Handler serves it over HTTP.
+package status
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// MemoryStore is a worker.StatusStore for a single process. It keeps the
+// jobs forever, it is meant for tests and local development.
+type MemoryStore struct {
+	clock worker.Clock
+
+	mu   sync.Mutex
+	jobs map[string]worker.JobStatus
+}
+
+var _ worker.StatusStore = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore, timestamping the jobs with
+// clock. A nil clock means worker.SystemClock.
+func NewMemoryStore(clock worker.Clock) *MemoryStore {
+	if clock == nil {
+		clock = worker.SystemClock()
+	}
+
+	return &MemoryStore{
+		clock: clock,
+		jobs:  make(map[string]worker.JobStatus),
+	}
+}
+
+// Create records a pending job.
+func (s *MemoryStore) Create(_ context.Context, job worker.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	s.jobs[job.ID] = worker.JobStatus{
+		ID:        job.ID,
+		Handler:   job.Handler,
+		Queue:     job.Queue,
+		Args:      job.Args,
+		Status:    worker.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return nil
+}
+
+// Delete removes the state of a job.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.jobs, id)
+
+	return nil
+}
+
+// Update changes the state of a job.
+func (s *MemoryStore) Update(_ context.Context, id string, update worker.StatusUpdate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return worker.ErrJobNotFound
+	}
+
+	job.Status = update.Status
+	job.Attempts = update.Attempts
+	job.UpdatedAt = s.clock.Now()
+
+	if update.LastError != "" {
+		job.LastError = update.LastError
+	}
+
+	s.jobs[id] = job
+
+	return nil
+}
+
+// Get returns the state of a job.
+func (s *MemoryStore) Get(_ context.Context, id string) (worker.JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return worker.JobStatus{}, worker.ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// List returns the state of the jobs matching filter.
+func (s *MemoryStore) List(_ context.Context, filter worker.StatusFilter) ([]worker.JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []worker.JobStatus
+
+	for _, job := range s.jobs {
+		if filter.Status != "" && job.Status != filter.Status {
+			continue
+		}
+
+		if filter.Handler != "" && job.Handler != filter.Handler {
+			continue
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].UpdatedAt.Equal(jobs[j].UpdatedAt) {
+			return jobs[i].UpdatedAt.After(jobs[j].UpdatedAt)
+		}
+
+		return jobs[i].ID > jobs[j].ID
+	})
+
+	if filter.Limit > 0 && len(jobs) > filter.Limit {
+		jobs = jobs[:filter.Limit]
+	}
+
+	return jobs, nil
+}
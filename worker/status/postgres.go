@@ -0,0 +1,235 @@
+package status
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// DefaultTable is the name of the table of PGStore.
+const DefaultTable = "worker_job_statuses"
+
+// PGStore is a worker.StatusStore on a PostgreSQL table.
+type PGStore struct {
+	db    *sql.DB
+	table string
+}
+
+var _ worker.StatusStore = (*PGStore)(nil)
+
+// NewPGStore returns a PGStore on table, DefaultTable when empty.
+func NewPGStore(db *sql.DB, table string) *PGStore {
+	if table == "" {
+		table = DefaultTable
+	}
+
+	return &PGStore{db: db, table: table}
+}
+
+// Migrate creates the statuses table if it does not exist.
+func (s *PGStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %[1]s (
+	id         TEXT PRIMARY KEY,
+	handler    TEXT NOT NULL,
+	queue      TEXT NOT NULL DEFAULT '',
+	args       JSONB NOT NULL DEFAULT '{}',
+	status     TEXT NOT NULL,
+	attempts   INT NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS %[1]s_status_idx
+	ON %[1]s (status, updated_at DESC);
+`, s.table))
+	if err != nil {
+		return fmt.Errorf("create job statuses table: %w", err)
+	}
+
+	return nil
+}
+
+// Create records a pending job.
+func (s *PGStore) Create(ctx context.Context, job worker.Job) error {
+	args := job.Args
+	if args == nil {
+		args = worker.Args{}
+	}
+
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (id, handler, queue, args, status)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (id) DO UPDATE SET
+	handler = EXCLUDED.handler,
+	queue = EXCLUDED.queue,
+	args = EXCLUDED.args,
+	status = EXCLUDED.status,
+	attempts = 0,
+	last_error = '',
+	created_at = now(),
+	updated_at = now()`, s.table),
+		job.ID,
+		job.Handler,
+		job.Queue,
+		args.String(),
+		worker.StatusPending,
+	)
+	if err != nil {
+		return fmt.Errorf("create job status: %w", err)
+	}
+
+	return nil
+}
+
+// Update changes the state of a job.
+func (s *PGStore) Update(ctx context.Context, id string, update worker.StatusUpdate) error {
+	res, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+UPDATE %s
+SET status = $1,
+	attempts = $2,
+	last_error = CASE WHEN $3 = '' THEN last_error ELSE $3 END,
+	updated_at = now()
+WHERE id = $4`, s.table),
+		update.Status,
+		update.Attempts,
+		update.LastError,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("update job status: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return worker.ErrJobNotFound
+	}
+
+	return nil
+}
+
+// Delete removes the state of a job.
+func (s *PGStore) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, s.table),
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("delete job status: %w", err)
+	}
+
+	return nil
+}
+
+// columns are the columns scanned by scan.
+const columns = `id, handler, queue, args, status, attempts, last_error, created_at, updated_at`
+
+// Get returns the state of a job.
+func (s *PGStore) Get(ctx context.Context, id string) (worker.JobStatus, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, columns, s.table),
+		id,
+	)
+
+	job, err := scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return worker.JobStatus{}, worker.ErrJobNotFound
+	}
+
+	if err != nil {
+		return worker.JobStatus{}, fmt.Errorf("get job status: %w", err)
+	}
+
+	return job, nil
+}
+
+// List returns the state of the jobs matching filter.
+func (s *PGStore) List(ctx context.Context, filter worker.StatusFilter) ([]worker.JobStatus, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	if filter.Handler != "" {
+		args = append(args, filter.Handler)
+		conditions = append(conditions, fmt.Sprintf("handler = $%d", len(args)))
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM %s`, columns, s.table)
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY updated_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list job statuses: %w", err)
+	}
+
+	defer rows.Close()
+
+	var jobs []worker.JobStatus
+
+	for rows.Next() {
+		job, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan job status: %w", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list job statuses: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// scan reads the columns of a job.
+func scan(row interface{ Scan(...any) error }) (worker.JobStatus, error) {
+	var (
+		job  worker.JobStatus
+		args []byte
+	)
+
+	err := row.Scan(
+		&job.ID,
+		&job.Handler,
+		&job.Queue,
+		&args,
+		&job.Status,
+		&job.Attempts,
+		&job.LastError,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return worker.JobStatus{}, err
+	}
+
+	if err := json.Unmarshal(args, &job.Args); err != nil {
+		return worker.JobStatus{}, fmt.Errorf("decode args: %w", err)
+	}
+
+	return job, nil
+}
@@ -0,0 +1,112 @@
+package status_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/internal/pgtest"
+	"github.com/purposeinplay/go-commons/worker/status"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPGStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := pgtest.Store(t, "job_statuses", status.NewPGStore)
+
+	r.NoError(store.Create(ctx, worker.Job{
+		ID:      "1",
+		Handler: "send_email",
+		Args:    worker.Args{"to": "john"},
+	}))
+	r.NoError(store.Create(ctx, worker.Job{ID: "2", Handler: "charge"}))
+
+	// Let the next update be the most recent one.
+	time.Sleep(10 * time.Millisecond)
+
+	r.NoError(store.Update(ctx, "1", worker.StatusUpdate{
+		Status:    worker.StatusFailed,
+		Attempts:  1,
+		LastError: "timeout",
+	}))
+
+	// An update without error keeps the last one.
+	r.NoError(store.Update(ctx, "1", worker.StatusUpdate{
+		Status:   worker.StatusRunning,
+		Attempts: 2,
+	}))
+
+	job, err := store.Get(ctx, "1")
+	r.NoError(err)
+	r.Equal("send_email", job.Handler)
+	r.Equal(worker.Args{"to": "john"}, job.Args)
+	r.Equal(worker.StatusRunning, job.Status)
+	r.Equal(2, job.Attempts)
+	r.Equal("timeout", job.LastError)
+
+	_, err = store.Get(ctx, "3")
+	r.ErrorIs(err, worker.ErrJobNotFound)
+	r.ErrorIs(store.Update(ctx, "3", worker.StatusUpdate{}), worker.ErrJobNotFound)
+
+	jobs, err := store.List(ctx, worker.StatusFilter{Handler: "charge"})
+	r.NoError(err)
+	r.Len(jobs, 1)
+	r.Equal("2", jobs[0].ID)
+	r.Equal(worker.StatusPending, jobs[0].Status)
+
+	jobs, err = store.List(ctx, worker.StatusFilter{Status: worker.StatusRunning})
+	r.NoError(err)
+	r.Len(jobs, 1)
+	r.Equal("1", jobs[0].ID)
+
+	// The most recently updated job comes first.
+	jobs, err = store.List(ctx, worker.StatusFilter{Limit: 1})
+	r.NoError(err)
+	r.Len(jobs, 1)
+	r.Equal("1", jobs[0].ID)
+
+	// Creating a job again resets its state.
+	r.NoError(store.Create(ctx, worker.Job{ID: "1", Handler: "send_email"}))
+
+	job, err = store.Get(ctx, "1")
+	r.NoError(err)
+	r.Equal(worker.StatusPending, job.Status)
+	r.Zero(job.Attempts)
+	r.Empty(job.LastError)
+
+	r.NoError(store.Delete(ctx, "1"))
+	r.NoError(store.Delete(ctx, "3"))
+
+	_, err = store.Get(ctx, "1")
+	r.ErrorIs(err, worker.ErrJobNotFound)
+}
+
+func TestPGStore_ConcurrentUpdate(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := pgtest.Store(t, "job_statuses", status.NewPGStore)
+
+	r.NoError(store.Create(ctx, worker.Job{ID: "1", Handler: "charge"}))
+
+	const attempts = 10
+
+	// The replicas running the attempts update the job concurrently.
+	pgtest.Concurrently(t, attempts, func(i int) error {
+		return store.Update(ctx, "1", worker.StatusUpdate{
+			Status:    worker.StatusFailed,
+			Attempts:  i + 1,
+			LastError: "failed",
+		})
+	})
+
+	job, err := store.Get(ctx, "1")
+	r.NoError(err)
+	r.Equal(worker.StatusFailed, job.Status)
+	r.GreaterOrEqual(job.Attempts, 1)
+	r.LessOrEqual(job.Attempts, attempts)
+	r.Equal("failed", job.LastError)
+}
@@ -0,0 +1,174 @@
+package status_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/memw"
+	"github.com/purposeinplay/go-commons/worker/status"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	clock := worker.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	store := status.NewMemoryStore(clock)
+
+	r.NoError(store.Create(ctx, worker.Job{ID: "1", Handler: "send_email"}))
+
+	clock.Advance(time.Second)
+
+	r.NoError(store.Create(ctx, worker.Job{ID: "2", Handler: "charge"}))
+
+	clock.Advance(time.Second)
+
+	r.NoError(store.Update(ctx, "1", worker.StatusUpdate{
+		Status:    worker.StatusFailed,
+		Attempts:  1,
+		LastError: "timeout",
+	}))
+
+	// An update without error keeps the last one.
+	r.NoError(store.Update(ctx, "1", worker.StatusUpdate{
+		Status:   worker.StatusRunning,
+		Attempts: 2,
+	}))
+
+	job, err := store.Get(ctx, "1")
+	r.NoError(err)
+	r.Equal(worker.StatusRunning, job.Status)
+	r.Equal(2, job.Attempts)
+	r.Equal("timeout", job.LastError)
+
+	_, err = store.Get(ctx, "3")
+	r.ErrorIs(err, worker.ErrJobNotFound)
+	r.ErrorIs(store.Update(ctx, "3", worker.StatusUpdate{}), worker.ErrJobNotFound)
+
+	jobs, err := store.List(ctx, worker.StatusFilter{Handler: "charge"})
+	r.NoError(err)
+	r.Len(jobs, 1)
+	r.Equal("2", jobs[0].ID)
+
+	// The most recently updated job comes first.
+	jobs, err = store.List(ctx, worker.StatusFilter{Limit: 1})
+	r.NoError(err)
+	r.Len(jobs, 1)
+	r.Equal("1", jobs[0].ID)
+
+	r.NoError(store.Delete(ctx, "1"))
+	r.NoError(store.Delete(ctx, "3"))
+
+	_, err = store.Get(ctx, "1")
+	r.ErrorIs(err, worker.ErrJobNotFound)
+}
+
+func TestInspector_Retry(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := status.NewMemoryStore(nil)
+
+	w := memw.New(memw.Options{
+		RetryPolicy: worker.NoRetry(),
+		StatusStore: store,
+	})
+
+	fail := true
+
+	r.NoError(w.RegisterContext("flaky", func(context.Context, worker.Args) error {
+		if fail {
+			return errors.New("failed")
+		}
+
+		return nil
+	}))
+
+	id, err := worker.Perform(ctx, w, worker.Job{Handler: "flaky"})
+	r.NoError(err)
+	r.NoError(w.RunUntilIdle(ctx))
+
+	inspector := status.NewInspector(store, w)
+
+	job, err := inspector.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(worker.StatusDead, job.Status)
+	r.Equal(1, job.Attempts)
+	r.Equal("failed", job.LastError)
+
+	fail = false
+
+	r.NoError(inspector.Retry(ctx, id))
+	r.NoError(w.RunUntilIdle(ctx))
+
+	job, err = inspector.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(worker.StatusSucceeded, job.Status)
+
+	// Only the dead jobs are retried.
+	r.ErrorIs(inspector.Retry(ctx, id), status.ErrNotDead)
+	r.ErrorIs(inspector.Retry(ctx, "unknown"), worker.ErrJobNotFound)
+}
+
+func TestHandler(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := status.NewMemoryStore(nil)
+
+	w := memw.New(memw.Options{StatusStore: store})
+
+	r.NoError(w.Register("send_email", func(worker.Args) error { return nil }))
+
+	id, err := worker.Perform(ctx, w, worker.Job{Handler: "send_email"})
+	r.NoError(err)
+
+	srv := httptest.NewServer(status.Handler(status.NewInspector(store, w)))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/jobs?status=pending&limit=10")
+	r.NoError(err)
+
+	var list struct {
+		Jobs []worker.JobStatus `json:"jobs"`
+	}
+
+	r.NoError(json.NewDecoder(resp.Body).Decode(&list))
+	r.NoError(resp.Body.Close())
+	r.Equal(http.StatusOK, resp.StatusCode)
+	r.Len(list.Jobs, 1)
+	r.Equal(id, list.Jobs[0].ID)
+
+	resp, err = http.Get(srv.URL + "/jobs/" + id)
+	r.NoError(err)
+
+	var job worker.JobStatus
+
+	r.NoError(json.NewDecoder(resp.Body).Decode(&job))
+	r.NoError(resp.Body.Close())
+	r.Equal(http.StatusOK, resp.StatusCode)
+	r.Equal("send_email", job.Handler)
+
+	resp, err = http.Get(srv.URL + "/jobs/unknown")
+	r.NoError(err)
+	r.NoError(resp.Body.Close())
+	r.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Post(srv.URL+"/jobs/"+id+"/retry", "", nil)
+	r.NoError(err)
+	r.NoError(resp.Body.Close())
+	r.Equal(http.StatusConflict, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/jobs?limit=-1")
+	r.NoError(err)
+	r.NoError(resp.Body.Close())
+	r.Equal(http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// The args of the typed jobs.
+const (
+	// payloadArg holds the encoded payload, in base64.
+	payloadArg = "payload"
+	// codecArg holds the name of the codec of the payload.
+	codecArg = "codec"
+)
+
+// Typed job errors.
+var (
+	// ErrInvalidPayload is returned when the payload of a typed job is not
+	// valid, or can't be encoded or decoded.
+	ErrInvalidPayload = errors.New("invalid payload")
+
+	// ErrCodecMismatch is returned when a typed job is decoded with
+	// another codec than the one it was encoded with.
+	ErrCodecMismatch = errors.New("codec mismatch")
+)
+
+// Validator is implemented by the payloads validated before they are
+// enqueued, like the messages generated by protoc-gen-validate.
+type Validator interface {
+	Validate() error
+}
+
+// TypedOption configures the typed helpers.
+type TypedOption func(*typedConfig)
+
+type typedConfig struct {
+	codec Codec
+}
+
+// WithCodec sets the codec of the payloads. The handler must be
+// registered with the codec its jobs are performed with. Defaults to
+// JSONCodec.
+func WithCodec(codec Codec) TypedOption {
+	return func(c *typedConfig) {
+		c.codec = codec
+	}
+}
+
+func newTypedConfig(opts []TypedOption) typedConfig {
+	cfg := typedConfig{codec: JSONCodec{}}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// EncodeArgs validates payload, when it is a Validator, and encodes it
+// into the args of a typed job. The payload goes through the worker
+// encoded as a string, so its numbers keep their precision.
+func EncodeArgs(payload any, opts ...TypedOption) (Args, error) {
+	cfg := newTypedConfig(opts)
+
+	if v, ok := payload.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+		}
+	}
+
+	data, err := cfg.codec.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: encode: %w", ErrInvalidPayload, err)
+	}
+
+	return Args{
+		payloadArg: base64.StdEncoding.EncodeToString(data),
+		codecArg:   cfg.codec.Name(),
+	}, nil
+}
+
+// DecodeArgs decodes the payload of a typed job from its args.
+func DecodeArgs[T any](args Args, opts ...TypedOption) (T, error) {
+	cfg := newTypedConfig(opts)
+
+	var payload T
+
+	if codec, _ := args[codecArg].(string); codec != cfg.codec.Name() {
+		return payload, fmt.Errorf("%w: got %q, want %q", ErrCodecMismatch, codec, cfg.codec.Name())
+	}
+
+	encoded, ok := args[payloadArg].(string)
+	if !ok {
+		return payload, fmt.Errorf("%w: missing payload", ErrInvalidPayload)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return payload, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	if err := cfg.codec.Unmarshal(data, &payload); err != nil {
+		return payload, fmt.Errorf("%w: decode: %w", ErrInvalidPayload, err)
+	}
+
+	return payload, nil
+}
+
+// RegisterTyped registers on w a handler given the payload of its jobs,
+// decoded into T. The jobs must be performed with PerformTyped, or with
+// the args returned by EncodeArgs.
+func RegisterTyped[T any](w Worker, name string, h func(context.Context, T) error, opts ...TypedOption) error {
+	return RegisterContext(w, name, TypedHandler(h, opts...))
+}
+
+// TypedHandler returns the ContextHandler decoding the payload of the jobs
+// into T before calling h, to register with the options of a worker. A
+// payload that can't be decoded fails the job with a Permanent error, it
+// is not retried.
+func TypedHandler[T any](h func(context.Context, T) error, opts ...TypedOption) ContextHandler {
+	return func(ctx context.Context, args Args) error {
+		payload, err := DecodeArgs[T](args, opts...)
+		if err != nil {
+			return Permanent(err)
+		}
+
+		return h(ctx, payload)
+	}
+}
+
+// PerformTyped performs job on w with payload as its args, encoded with
+// EncodeArgs. The Args of job are replaced.
+func PerformTyped[T any](ctx context.Context, w Worker, job Job, payload T, opts ...TypedOption) error {
+	args, err := EncodeArgs(payload, opts...)
+	if err != nil {
+		return err
+	}
+
+	job.Args = args
+
+	return PerformContext(ctx, w, job)
+}
@@ -0,0 +1,114 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/memw"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type transfer struct {
+	ID     string
+	Amount int64
+}
+
+func (t transfer) Validate() error {
+	if t.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+
+	return nil
+}
+
+func TestTyped(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := memw.New(memw.Options{})
+
+	var got transfer
+
+	r.NoError(worker.RegisterTyped(w, "transfer", func(_ context.Context, tr transfer) error {
+		got = tr
+		return nil
+	}))
+
+	// The number would lose precision as a float64.
+	want := transfer{ID: "1", Amount: 1<<60 + 1}
+
+	r.NoError(worker.PerformTyped(ctx, w, worker.Job{Handler: "transfer"}, want))
+	r.NoError(w.RunUntilIdle(ctx))
+	r.Equal(want, got)
+
+	// The payload is validated on enqueue.
+	err := worker.PerformTyped(ctx, w, worker.Job{Handler: "transfer"}, transfer{ID: "2"})
+	r.ErrorIs(err, worker.ErrInvalidPayload)
+	r.Empty(w.Enqueued("transfer"))
+}
+
+func TestTyped_Proto(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := memw.New(memw.Options{RetryPolicy: worker.NoRetry()})
+
+	var got int64
+
+	r.NoError(worker.RegisterTyped(w, "proto", func(_ context.Context, v *wrapperspb.Int64Value) error {
+		got = v.GetValue()
+		return nil
+	}, worker.WithCodec(worker.ProtoCodec{})))
+
+	r.NoError(worker.PerformTyped(
+		ctx,
+		w,
+		worker.Job{Handler: "proto"},
+		wrapperspb.Int64(42),
+		worker.WithCodec(worker.ProtoCodec{}),
+	))
+	r.NoError(w.RunUntilIdle(ctx))
+	r.Equal(int64(42), got)
+
+	// A job encoded with another codec fails.
+	r.NoError(worker.PerformTyped(ctx, w, worker.Job{Handler: "proto"}, wrapperspb.Int64(1)))
+	r.NoError(w.RunUntilIdle(ctx))
+
+	dead := w.Dead("proto")
+	r.Len(dead, 1)
+	r.ErrorIs(dead[0].Err, worker.ErrCodecMismatch)
+}
+
+func TestTyped_InvalidPayload(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := memw.New(memw.Options{RetryPolicy: worker.DefaultRetryPolicy()})
+
+	r.NoError(worker.RegisterTyped(w, "proto", func(context.Context, *wrapperspb.Int64Value) error {
+		return nil
+	}, worker.WithCodec(worker.ProtoCodec{})))
+
+	// A payload that can't be decoded is dead without being retried.
+	r.NoError(worker.PerformTyped(ctx, w, worker.Job{Handler: "proto"}, wrapperspb.Int64(1)))
+	r.NoError(w.RunUntilIdle(ctx))
+
+	dead := w.Dead("proto")
+	r.Len(dead, 1)
+	r.Equal(1, dead[0].Attempts)
+	r.True(worker.IsPermanent(dead[0].Err))
+	r.ErrorIs(dead[0].Err, worker.ErrCodecMismatch)
+}
+
+func TestDecodeArgs(t *testing.T) {
+	r := require.New(t)
+
+	_, err := worker.DecodeArgs[transfer](worker.Args{"id": 1})
+	r.ErrorIs(err, worker.ErrCodecMismatch)
+
+	_, err = worker.DecodeArgs[transfer](worker.Args{"codec": "json"})
+	r.ErrorIs(err, worker.ErrInvalidPayload)
+}
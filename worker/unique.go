@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultUniqueFor is how long a unique job blocks its duplicates when
+// Job.UniqueFor is zero.
+const DefaultUniqueFor = time.Hour
+
+// Unique job errors.
+var (
+	// ErrDuplicateJob is returned when a job is performed while an
+	// identical one is pending.
+	ErrDuplicateJob = errors.New("duplicate job")
+
+	// ErrNoUniqueStore is returned when a unique job is performed by a
+	// worker without UniqueStore.
+	ErrNoUniqueStore = errors.New("no unique store")
+)
+
+// DuplicatePolicy tells what happens to a job performed while an
+// identical one is pending.
+type DuplicatePolicy int
+
+const (
+	// RejectDuplicate returns ErrDuplicateJob.
+	RejectDuplicate DuplicatePolicy = iota
+	// CoalesceDuplicate drops the job silently, the pending one runs.
+	CoalesceDuplicate
+)
+
+// UniqueStore reserves the unique keys of the pending jobs.
+type UniqueStore interface {
+	// Acquire reserves key for ttl. It reports false when the key is
+	// already reserved.
+	Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// Release frees key.
+	Release(ctx context.Context, key string) error
+}
+
+// AcquireUnique reserves the unique key of job in store, the workers call
+// it when a job is performed. It reports whether the job should be
+// enqueued: a duplicate is rejected with ErrDuplicateJob or coalesced,
+// following its DuplicatePolicy.
+func AcquireUnique(ctx context.Context, store UniqueStore, job Job) (bool, error) {
+	if job.UniqueKey == "" {
+		return true, nil
+	}
+
+	if store == nil {
+		return false, ErrNoUniqueStore
+	}
+
+	ttl := job.UniqueFor
+	if ttl <= 0 {
+		ttl = DefaultUniqueFor
+	}
+
+	acquired, err := store.Acquire(ctx, uniqueKey(job), ttl)
+	if err != nil {
+		return false, fmt.Errorf("acquire unique key: %w", err)
+	}
+
+	if acquired {
+		return true, nil
+	}
+
+	if job.OnDuplicate == CoalesceDuplicate {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("%w: %s %q", ErrDuplicateJob, job.Handler, job.UniqueKey)
+}
+
+// ReleaseUnique frees the unique key of job, the workers call it when the
+// job starts running, so an identical job can be performed meanwhile.
+func ReleaseUnique(ctx context.Context, store UniqueStore, job Job) error {
+	if job.UniqueKey == "" || store == nil {
+		return nil
+	}
+
+	if err := store.Release(ctx, uniqueKey(job)); err != nil {
+		return fmt.Errorf("release unique key: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueKey scopes the unique key of a job to its handler.
+func uniqueKey(job Job) string {
+	return job.Handler + ":" + job.UniqueKey
+}
@@ -0,0 +1,80 @@
+package unique
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// DefaultTable is the name of the table of PGStore.
+const DefaultTable = "worker_unique_keys"
+
+// PGStore is a worker.UniqueStore on a PostgreSQL table.
+type PGStore struct {
+	db    *sql.DB
+	table string
+}
+
+var _ worker.UniqueStore = (*PGStore)(nil)
+
+// NewPGStore returns a PGStore on table, DefaultTable when empty.
+func NewPGStore(db *sql.DB, table string) *PGStore {
+	if table == "" {
+		table = DefaultTable
+	}
+
+	return &PGStore{db: db, table: table}
+}
+
+// Migrate creates the keys table if it does not exist.
+func (s *PGStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	key        TEXT PRIMARY KEY,
+	expires_at TIMESTAMPTZ NOT NULL
+)`, s.table))
+	if err != nil {
+		return fmt.Errorf("create unique keys table: %w", err)
+	}
+
+	return nil
+}
+
+// Acquire reserves key for ttl, unless it is reserved and not expired.
+func (s *PGStore) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	res, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %[1]s (key, expires_at)
+VALUES ($1, now() + ($2::float8 * interval '1 second'))
+ON CONFLICT (key) DO UPDATE SET expires_at = EXCLUDED.expires_at
+WHERE %[1]s.expires_at <= now()`, s.table),
+		key,
+		ttl.Seconds(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("acquire key: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("acquire key: %w", err)
+	}
+
+	return n == 1, nil
+}
+
+// Release frees key.
+func (s *PGStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE key = $1`, s.table),
+		key,
+	)
+	if err != nil {
+		return fmt.Errorf("release key: %w", err)
+	}
+
+	return nil
+}
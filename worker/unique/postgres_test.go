@@ -0,0 +1,63 @@
+package unique_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker/internal/pgtest"
+	"github.com/purposeinplay/go-commons/worker/unique"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPGStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := pgtest.Store(t, "unique_keys", unique.NewPGStore)
+
+	acquired, err := store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+
+	acquired, err = store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.False(acquired)
+
+	r.NoError(store.Release(ctx, "key"))
+
+	acquired, err = store.Acquire(ctx, "key", time.Millisecond)
+	r.NoError(err)
+	r.True(acquired)
+
+	// The key expires.
+	time.Sleep(10 * time.Millisecond)
+
+	acquired, err = store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+}
+
+func TestPGStore_ConcurrentAcquire(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := pgtest.Store(t, "unique_keys", unique.NewPGStore)
+
+	const producers = 10
+
+	var acquired atomic.Int32
+
+	// Only one of the producers reserves the key.
+	pgtest.Concurrently(t, producers, func(int) error {
+		ok, err := store.Acquire(ctx, "key", time.Minute)
+		if ok {
+			acquired.Add(1)
+		}
+
+		return err
+	})
+
+	r.Equal(int32(1), acquired.Load())
+}
@@ -0,0 +1,52 @@
+package unique
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisPrefix prefixes the keys of RedisStore.
+const DefaultRedisPrefix = "worker:unique:"
+
+// RedisStore is a worker.UniqueStore on Redis, the keys expire with the
+// Redis TTL. It takes any redis.UniversalClient: a single node, a cluster
+// or a sentinel failover client.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ worker.UniqueStore = (*RedisStore)(nil)
+
+// NewRedisStore returns a RedisStore whose keys are prefixed with prefix,
+// DefaultRedisPrefix when empty.
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = DefaultRedisPrefix
+	}
+
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Acquire reserves key for ttl with SET NX.
+func (s *RedisStore) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	acquired, err := s.client.SetNX(ctx, s.prefix+key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("set key: %w", err)
+	}
+
+	return acquired, nil
+}
+
+// Release frees key.
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil {
+		return fmt.Errorf("delete key: %w", err)
+	}
+
+	return nil
+}
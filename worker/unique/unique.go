@@ -0,0 +1,67 @@
+// Package unique implements worker.UniqueStore in memory, on Redis and on
+// PostgreSQL.
+package unique
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// MemoryStore is a worker.UniqueStore for a single process.
+type MemoryStore struct {
+	clock worker.Clock
+
+	mu       sync.Mutex
+	expiries map[string]time.Time
+}
+
+var _ worker.UniqueStore = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore, the keys expire on clock.
+// A nil clock means worker.SystemClock.
+func NewMemoryStore(clock worker.Clock) *MemoryStore {
+	if clock == nil {
+		clock = worker.SystemClock()
+	}
+
+	return &MemoryStore{
+		clock:    clock,
+		expiries: make(map[string]time.Time),
+	}
+}
+
+// Acquire reserves key for ttl, unless it is reserved and not expired.
+func (s *MemoryStore) Acquire(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	if expiry, ok := s.expiries[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+
+	s.expiries[key] = now.Add(ttl)
+
+	// Forget the expired keys, so the map does not grow forever.
+	for k, expiry := range s.expiries {
+		if !now.Before(expiry) {
+			delete(s.expiries, k)
+		}
+	}
+
+	return true, nil
+}
+
+// Release frees key.
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.expiries, key)
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package unique_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/unique"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	clock := worker.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	store := unique.NewMemoryStore(clock)
+
+	acquired, err := store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+
+	acquired, err = store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.False(acquired)
+
+	// The key expires.
+	clock.Advance(time.Minute)
+
+	acquired, err = store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+
+	r.NoError(store.Release(ctx, "key"))
+
+	acquired, err = store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+}
+
+func TestRedisStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+
+	store := unique.NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "")
+
+	acquired, err := store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+	r.True(mr.Exists(unique.DefaultRedisPrefix + "key"))
+
+	acquired, err = store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.False(acquired)
+
+	// The key expires.
+	mr.FastForward(time.Minute)
+
+	acquired, err = store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+
+	r.NoError(store.Release(ctx, "key"))
+
+	acquired, err = store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+}
+
+func TestRedisStore_UniversalClient(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+
+	client := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{mr.Addr()}})
+
+	store := unique.NewRedisStore(client, "jobs:")
+
+	acquired, err := store.Acquire(ctx, "key", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+	r.True(mr.Exists("jobs:key"))
+}
@@ -9,6 +9,20 @@ import (
 // a slice of arguments.
 type Handler func(Args) error
 
+// ContextHandler is a Handler that is given the context of the job. The
+// context is cancelled when the worker stops or the job times out, and it
+// carries the trace context and request id of the context the job was
+// performed with.
+type ContextHandler func(context.Context, Args) error
+
+// AdaptHandler turns a Handler into a ContextHandler that ignores the
+// context.
+func AdaptHandler(h Handler) ContextHandler {
+	return func(_ context.Context, args Args) error {
+		return h(args)
+	}
+}
+
 // Worker describes how a worker should be implemented.
 type Worker interface {
 	// Start the worker with the given context
@@ -29,3 +43,39 @@ type Worker interface {
 	// Register a Handler
 	Register(string, Handler) error
 }
+
+// ContextWorker is a Worker giving the context of the jobs to their
+// handlers. PerformContext and RegisterContext fall back on the methods of
+// Worker for the workers that don't implement it.
+type ContextWorker interface {
+	Worker
+
+	// PerformContext performs a job as soon as possible, propagating the
+	// trace context and request id of ctx to the handler
+	PerformContext(context.Context, Job) error
+
+	// RegisterContext registers a ContextHandler
+	RegisterContext(string, ContextHandler) error
+}
+
+// PerformContext performs job on w with ctx when w is a ContextWorker,
+// with Perform otherwise.
+func PerformContext(ctx context.Context, w Worker, job Job) error {
+	if cw, ok := w.(ContextWorker); ok {
+		return cw.PerformContext(ctx, job)
+	}
+
+	return w.Perform(job)
+}
+
+// RegisterContext registers h on w when w is a ContextWorker. Otherwise h
+// is registered as a Handler, given a background context.
+func RegisterContext(w Worker, name string, h ContextHandler) error {
+	if cw, ok := w.(ContextWorker); ok {
+		return cw.RegisterContext(name, h)
+	}
+
+	return w.Register(name, func(args Args) error {
+		return h(context.Background(), args)
+	})
+}
@@ -0,0 +1,56 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/stretchr/testify/require"
+)
+
+// plainWorker is a Worker without the context methods, running the jobs
+// as they are performed.
+type plainWorker struct {
+	handlers map[string]worker.Handler
+}
+
+func (*plainWorker) Start(context.Context) error { return nil }
+
+func (*plainWorker) Stop() error { return nil }
+
+func (w *plainWorker) Perform(job worker.Job) error {
+	return w.handlers[job.Handler](job.Args)
+}
+
+func (w *plainWorker) PerformAt(job worker.Job, _ time.Time) error { return w.Perform(job) }
+
+func (w *plainWorker) PerformIn(job worker.Job, _ time.Duration) error { return w.Perform(job) }
+
+func (w *plainWorker) Register(name string, h worker.Handler) error {
+	w.handlers[name] = h
+
+	return nil
+}
+
+func TestContextWorker_Fallback(t *testing.T) {
+	r := require.New(t)
+
+	w := &plainWorker{handlers: map[string]worker.Handler{}}
+
+	var got worker.Args
+
+	r.NoError(worker.RegisterContext(w, "send", func(ctx context.Context, args worker.Args) error {
+		r.NotNil(ctx)
+
+		got = args
+
+		return nil
+	}))
+
+	r.NoError(worker.PerformContext(context.Background(), w, worker.Job{
+		Handler: "send",
+		Args:    worker.Args{"to": "john"},
+	}))
+	r.Equal(worker.Args{"to": "john"}, got)
+}
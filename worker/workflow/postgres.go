@@ -0,0 +1,165 @@
+package workflow
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DefaultTable is the name of the table of PGStore.
+const DefaultTable = "worker_workflows"
+
+// PGStore is a Store on a PostgreSQL table, the updates lock the row of
+// the workflow.
+type PGStore struct {
+	db    *sql.DB
+	table string
+}
+
+var _ Store = (*PGStore)(nil)
+
+// NewPGStore returns a PGStore on table, DefaultTable when empty.
+func NewPGStore(db *sql.DB, table string) *PGStore {
+	if table == "" {
+		table = DefaultTable
+	}
+
+	return &PGStore{db: db, table: table}
+}
+
+// Migrate creates the workflows table if it does not exist.
+func (s *PGStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %[1]s (
+	id         TEXT PRIMARY KEY,
+	status     TEXT NOT NULL,
+	state      JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS %[1]s_status_idx
+	ON %[1]s (status, updated_at DESC);
+`, s.table))
+	if err != nil {
+		return fmt.Errorf("create workflows table: %w", err)
+	}
+
+	return nil
+}
+
+// Create records a new workflow.
+func (s *PGStore) Create(ctx context.Context, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode workflow: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (id, status, state, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5)`, s.table),
+		state.ID,
+		state.Status,
+		data,
+		state.CreatedAt,
+		state.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create workflow: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the state of a workflow.
+func (s *PGStore) Get(ctx context.Context, id string) (State, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(`SELECT state FROM %s WHERE id = $1`, s.table),
+		id,
+	)
+
+	state, err := scan(row)
+	if err != nil {
+		return State{}, fmt.Errorf("get workflow: %w", err)
+	}
+
+	return state, nil
+}
+
+// Update applies fn to the state of a workflow, in a transaction holding
+// the lock of its row.
+func (s *PGStore) Update(ctx context.Context, id string, fn func(*State) bool) (State, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return State{}, fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	row := tx.QueryRowContext(
+		ctx,
+		fmt.Sprintf(`SELECT state FROM %s WHERE id = $1 FOR UPDATE`, s.table),
+		id,
+	)
+
+	state, err := scan(row)
+	if err != nil {
+		return State{}, fmt.Errorf("get workflow: %w", err)
+	}
+
+	if !fn(&state) {
+		return state, nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return State{}, fmt.Errorf("encode workflow: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+UPDATE %s
+SET status = $1,
+	state = $2,
+	updated_at = $3
+WHERE id = $4`, s.table),
+		state.Status,
+		data,
+		state.UpdatedAt,
+		id,
+	)
+	if err != nil {
+		return State{}, fmt.Errorf("update workflow: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return State{}, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return state, nil
+}
+
+// scan reads the state of a workflow.
+func scan(row *sql.Row) (State, error) {
+	var data []byte
+
+	if err := row.Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return State{}, ErrNotFound
+		}
+
+		return State{}, err
+	}
+
+	var state State
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("decode workflow: %w", err)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,98 @@
+package workflow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/internal/pgtest"
+	"github.com/purposeinplay/go-commons/worker/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPGStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := pgtest.Store(t, "workflows", workflow.NewPGStore)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r.NoError(store.Create(ctx, workflow.State{
+		ID:        "1",
+		Stages:    [][]worker.Job{{{Handler: "fetch"}}, {{Handler: "notify"}}},
+		OnFailure: &worker.Job{Handler: "cleanup"},
+		Status:    workflow.StatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+
+	state, err := store.Get(ctx, "1")
+	r.NoError(err)
+	r.Len(state.Stages, 2)
+	r.Equal("notify", state.Stages[1][0].Handler)
+	r.Equal("cleanup", state.OnFailure.Handler)
+
+	// The state is saved only when fn returns true.
+	state, err = store.Update(ctx, "1", func(s *workflow.State) bool {
+		s.Status = workflow.StatusFailed
+
+		return false
+	})
+	r.NoError(err)
+	r.Equal(workflow.StatusFailed, state.Status)
+
+	state, err = store.Get(ctx, "1")
+	r.NoError(err)
+	r.Equal(workflow.StatusRunning, state.Status)
+
+	_, err = store.Update(ctx, "1", func(s *workflow.State) bool {
+		s.Stage = 1
+		s.UpdatedAt = now.Add(time.Second)
+
+		return true
+	})
+	r.NoError(err)
+
+	state, err = store.Get(ctx, "1")
+	r.NoError(err)
+	r.Equal(1, state.Stage)
+	r.True(now.Add(time.Second).Equal(state.UpdatedAt))
+
+	_, err = store.Get(ctx, "2")
+	r.ErrorIs(err, workflow.ErrNotFound)
+
+	_, err = store.Update(ctx, "2", func(*workflow.State) bool { return true })
+	r.ErrorIs(err, workflow.ErrNotFound)
+}
+
+func TestPGStore_ConcurrentUpdate(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := pgtest.Store(t, "workflows", workflow.NewPGStore)
+
+	const jobs = 10
+
+	r.NoError(store.Create(ctx, workflow.State{
+		ID:     "1",
+		Status: workflow.StatusRunning,
+	}))
+
+	// The jobs of a group complete concurrently, the row lock keeps every
+	// completion.
+	pgtest.Concurrently(t, jobs, func(index int) error {
+		_, err := store.Update(ctx, "1", func(s *workflow.State) bool {
+			s.Completed = append(s.Completed, index)
+
+			return true
+		})
+
+		return err
+	})
+
+	state, err := store.Get(ctx, "1")
+	r.NoError(err)
+	r.ElementsMatch([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, state.Completed)
+}
@@ -0,0 +1,179 @@
+package workflow
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// Status is the state of a workflow.
+type Status string
+
+const (
+	// StatusRunning is the status of the workflows with jobs to run.
+	StatusRunning Status = "running"
+	// StatusSucceeded is the status of the workflows whose jobs all
+	// succeeded.
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed is the status of the workflows with a dead job.
+	StatusFailed Status = "failed"
+)
+
+// State is the state of a workflow kept in a Store.
+type State struct {
+	ID string `json:"id"`
+	// Stages are the jobs of the workflow, the jobs of a stage run in
+	// parallel.
+	Stages [][]worker.Job `json:"stages"`
+	// OnFailure is performed when the workflow fails.
+	OnFailure *worker.Job `json:"on_failure,omitempty"`
+	Status    Status      `json:"status"`
+	// Stage is the index of the running stage.
+	Stage int `json:"stage"`
+	// Completed are the indexes of the succeeded jobs of the running
+	// stage.
+	Completed []int `json:"completed,omitempty"`
+	// Dispatched tells whether the jobs of the running stage were
+	// performed. A stage reached but not dispatched, by a worker that
+	// stopped in between, is performed again when a job of the previous
+	// stage completes again.
+	Dispatched bool `json:"dispatched"`
+	// Error is the error of the dead job of a failed workflow.
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// complete records the success of the job at index of stage. It tells
+// whether the state changed, a job completed twice is ignored.
+func (s *State) complete(stage, index int) bool {
+	if s.Status != StatusRunning || stage != s.Stage || slices.Contains(s.Completed, index) {
+		return false
+	}
+
+	s.Completed = append(s.Completed, index)
+
+	if len(s.Completed) < len(s.Stages[s.Stage]) {
+		return true
+	}
+
+	s.Completed = nil
+	s.Stage++
+	s.Dispatched = false
+
+	if s.Stage == len(s.Stages) {
+		s.Status = StatusSucceeded
+	}
+
+	return true
+}
+
+// undispatched tells whether the stage after stage was reached but its
+// jobs were not performed.
+func (s *State) undispatched(stage int) bool {
+	return s.Status == StatusRunning && s.Stage == stage+1 && !s.Dispatched
+}
+
+// dispatch records that the jobs of stage were performed. It tells
+// whether the state changed.
+func (s *State) dispatch(stage int) bool {
+	if s.Stage != stage || s.Dispatched {
+		return false
+	}
+
+	s.Dispatched = true
+
+	return true
+}
+
+// fail marks a running workflow failed with reason. It tells whether the
+// state changed.
+func (s *State) fail(reason string) bool {
+	if s.Status != StatusRunning {
+		return false
+	}
+
+	s.Status = StatusFailed
+	s.Error = reason
+
+	return true
+}
+
+// Store keeps the state of the workflows, shared by the replicas of a
+// worker.
+type Store interface {
+	// Create records a new workflow.
+	Create(ctx context.Context, state State) error
+	// Get returns the state of a workflow, or ErrNotFound.
+	Get(ctx context.Context, id string) (State, error)
+	// Update applies fn to the state of a workflow, atomically with the
+	// other updates, and saves it when fn returns true. It returns the
+	// state after fn, or ErrNotFound.
+	Update(ctx context.Context, id string, fn func(*State) bool) (State, error)
+}
+
+// MemoryStore is a Store for a single process, meant for tests and local
+// development. It keeps the workflows forever.
+type MemoryStore struct {
+	mu        sync.Mutex
+	workflows map[string]State
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{workflows: make(map[string]State)}
+}
+
+// Create records a new workflow.
+func (s *MemoryStore) Create(_ context.Context, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.workflows[state.ID] = clone(state)
+
+	return nil
+}
+
+// Get returns the state of a workflow.
+func (s *MemoryStore) Get(_ context.Context, id string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.workflows[id]
+	if !ok {
+		return State{}, ErrNotFound
+	}
+
+	return clone(state), nil
+}
+
+// Update applies fn to the state of a workflow.
+func (s *MemoryStore) Update(_ context.Context, id string, fn func(*State) bool) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.workflows[id]
+	if !ok {
+		return State{}, ErrNotFound
+	}
+
+	state = clone(state)
+
+	if fn(&state) {
+		s.workflows[id] = clone(state)
+	}
+
+	return state, nil
+}
+
+// clone returns a copy of state not sharing its slices.
+func clone(state State) State {
+	state.Completed = slices.Clone(state.Completed)
+
+	return state
+}
@@ -0,0 +1,405 @@
+// Package workflow runs jobs of a worker.ContextWorker in sequence and in
+// parallel: Chain runs a job when the previous one succeeded, Group runs
+// jobs in parallel and Chord runs a callback once all of them succeeded.
+// When a job of a workflow is dead, the workflow fails and its optional
+// failure job is performed.
+//
+// The state of the workflows is kept in a Store, in memory or on
+// PostgreSQL, so a workflow resumes where it stopped when the worker
+// restarts with durable jobs: a stage reached by a worker that stopped
+// before performing it is performed when the job that completed the
+// previous stage is delivered again. The jobs run at least once, like the
+// other jobs of the worker.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/purposeinplay/go-commons/worker"
+)
+
+// The args added to the jobs of the workflows.
+const (
+	// StepArg holds the step of a job of a workflow, read by the
+	// middleware of the Engine.
+	StepArg = "workflow_step"
+	// IDArg holds the ID of the failed workflow in the args of its
+	// failure job.
+	IDArg = "workflow_id"
+	// ErrorArg holds the error of the dead job in the args of the failure
+	// job.
+	ErrorArg = "workflow_error"
+)
+
+// Workflow errors.
+var (
+	// ErrNotFound is returned when a Store has no workflow with an ID.
+	ErrNotFound = errors.New("workflow not found")
+
+	// ErrEmpty is returned when a workflow without jobs is started.
+	ErrEmpty = errors.New("empty workflow")
+)
+
+// Node is a part of a workflow, built with Job, Chain, Group and Chord.
+// It runs as a sequence of stages, the jobs of a stage run in parallel.
+type Node struct {
+	stages [][]worker.Job
+}
+
+// Job returns a Node running job.
+func Job(job worker.Job) Node {
+	return Node{stages: [][]worker.Job{{job}}}
+}
+
+// Chain returns a Node running nodes one after the other: a node starts
+// once all the jobs of the previous one succeeded.
+func Chain(nodes ...Node) Node {
+	var chain Node
+
+	for _, n := range nodes {
+		chain.stages = append(chain.stages, n.stages...)
+	}
+
+	return chain
+}
+
+// Group returns a Node running jobs in parallel.
+func Group(jobs ...worker.Job) Node {
+	if len(jobs) == 0 {
+		return Node{}
+	}
+
+	return Node{stages: [][]worker.Job{jobs}}
+}
+
+// Chord returns a Node running jobs in parallel, then callback once all
+// of them succeeded.
+func Chord(callback worker.Job, jobs ...worker.Job) Node {
+	return Chain(Group(jobs...), Job(callback))
+}
+
+// Options configures an Engine.
+type Options struct {
+	// Store keeps the state of the workflows. Defaults to a MemoryStore.
+	Store Store
+
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+
+	// Clock timestamps the workflows. Defaults to worker.SystemClock().
+	Clock worker.Clock
+}
+
+// Engine starts workflows on a worker and advances them as their jobs
+// complete. The handlers of the jobs must be registered with Register,
+// or wrapped with Middleware.
+type Engine struct {
+	worker worker.ContextWorker
+	store  Store
+	logger *slog.Logger
+	clock  worker.Clock
+}
+
+// New returns an Engine running the jobs of the workflows on w. The
+// handlers of w must be given the worker.JobInfo of their job, like the
+// ones of amqpw, memw and pgw: a workflow fails when the final attempt of
+// one of its jobs fails.
+func New(w worker.ContextWorker, opts Options) *Engine {
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = worker.SystemClock()
+	}
+
+	return &Engine{
+		worker: w,
+		store:  opts.Store,
+		logger: opts.Logger,
+		clock:  opts.Clock,
+	}
+}
+
+// StartOption configures a workflow.
+type StartOption func(*State)
+
+// WithOnFailure sets the job performed once when the workflow fails, with
+// the ID of the workflow and the error of the dead job in its IDArg and
+// ErrorArg args.
+func WithOnFailure(job worker.Job) StartOption {
+	return func(s *State) {
+		s.OnFailure = &job
+	}
+}
+
+// Start records the workflow of root and performs the jobs of its first
+// stage. It returns the ID of the workflow.
+func (e *Engine) Start(ctx context.Context, root Node, opts ...StartOption) (string, error) {
+	if len(root.stages) == 0 {
+		return "", ErrEmpty
+	}
+
+	now := e.clock.Now()
+
+	state := State{
+		ID:        worker.NewJobID(),
+		Stages:    root.stages,
+		Status:    StatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	for _, opt := range opts {
+		opt(&state)
+	}
+
+	if err := e.store.Create(ctx, state); err != nil {
+		return "", fmt.Errorf("create workflow: %w", err)
+	}
+
+	e.logger.Info(
+		"starting workflow",
+		slog.String("workflow", state.ID),
+		slog.Int("stages", len(state.Stages)),
+	)
+
+	if err := e.performStage(ctx, state); err != nil {
+		e.fail(ctx, state.ID, err)
+
+		return "", err
+	}
+
+	e.dispatch(ctx, state)
+
+	return state.ID, nil
+}
+
+// Get returns the state of a workflow, or ErrNotFound.
+func (e *Engine) Get(ctx context.Context, id string) (State, error) {
+	return e.store.Get(ctx, id)
+}
+
+// Register registers h on the worker, wrapped with Middleware.
+func (e *Engine) Register(name string, h worker.ContextHandler) error {
+	return e.worker.RegisterContext(name, worker.Chain(h, e.Middleware()))
+}
+
+// Middleware advances the workflows of the jobs it runs: once a job
+// succeeds, the next stage is performed when the job was the last of its
+// stage. When the final attempt of a job fails, as told by its
+// worker.JobInfo, or it fails with a worker.Permanent error, its workflow
+// fails.
+// The jobs that are not part of a workflow run as usual.
+//
+// An attempt cancelled with context.Canceled, by a worker stopping, does
+// not fail the workflow.
+func (e *Engine) Middleware() worker.Middleware {
+	return func(next worker.ContextHandler) worker.ContextHandler {
+		return func(ctx context.Context, args worker.Args) error {
+			s, ok := parseStep(args)
+			if !ok {
+				return next(ctx, args)
+			}
+
+			err := next(ctx, args)
+
+			// The workflow is advanced even when the job timed out.
+			ctx = context.WithoutCancel(ctx)
+
+			if err != nil {
+				info, _ := worker.JobInfoFromContext(ctx)
+
+				if (info.Final || worker.IsPermanent(err)) && !errors.Is(err, context.Canceled) {
+					e.fail(ctx, s.id, err)
+				}
+
+				return err
+			}
+
+			// Retry the job when its completion can't be recorded.
+			return e.complete(ctx, s)
+		}
+	}
+}
+
+// complete records the success of the job of step s, and performs the
+// next stage when it completed the current one. The advance is recorded
+// before the next stage is performed, so a job completed again performs
+// the next stage when it was not dispatched.
+func (e *Engine) complete(ctx context.Context, s step) error {
+	var advanced, resumed bool
+
+	state, err := e.store.Update(ctx, s.id, func(state *State) bool {
+		if !state.complete(s.stage, s.index) {
+			resumed = state.undispatched(s.stage)
+
+			return false
+		}
+
+		advanced = state.Stage > s.stage
+		state.UpdatedAt = e.clock.Now()
+
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("complete workflow step: %w", err)
+	}
+
+	if !advanced && !resumed {
+		return nil
+	}
+
+	if state.Status == StatusSucceeded {
+		e.logger.Info("workflow succeeded", slog.String("workflow", state.ID))
+
+		return nil
+	}
+
+	if resumed {
+		e.logger.Info(
+			"performing undispatched workflow stage",
+			slog.String("workflow", state.ID),
+			slog.Int("stage", state.Stage),
+		)
+	}
+
+	if err := e.performStage(ctx, state); err != nil {
+		// The stage can't be performed again, the workflow would be stuck.
+		e.fail(ctx, state.ID, err)
+
+		return nil
+	}
+
+	e.dispatch(ctx, state)
+
+	return nil
+}
+
+// dispatch records that the jobs of the current stage of state were
+// performed. When it fails, the stage may be performed again.
+func (e *Engine) dispatch(ctx context.Context, state State) {
+	_, err := e.store.Update(ctx, state.ID, func(s *State) bool {
+		return s.dispatch(state.Stage)
+	})
+	if err != nil {
+		e.logger.Error(
+			"unable to record workflow stage dispatch",
+			slog.String("workflow", state.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// fail marks the workflow id failed with cause, and performs its failure
+// job the first time.
+func (e *Engine) fail(ctx context.Context, id string, cause error) {
+	var failed bool
+
+	state, err := e.store.Update(ctx, id, func(state *State) bool {
+		failed = state.fail(cause.Error())
+		if failed {
+			state.UpdatedAt = e.clock.Now()
+		}
+
+		return failed
+	})
+	if err != nil {
+		e.logger.Error("unable to fail workflow", slog.String("workflow", id), slog.Any("error", err))
+
+		return
+	}
+
+	if !failed {
+		return
+	}
+
+	e.logger.Error("workflow failed", slog.String("workflow", id), slog.Any("error", cause))
+
+	if state.OnFailure == nil {
+		return
+	}
+
+	job := *state.OnFailure
+
+	job.Args = maps.Clone(job.Args)
+	if job.Args == nil {
+		job.Args = worker.Args{}
+	}
+
+	job.Args[IDArg] = id
+	job.Args[ErrorArg] = cause.Error()
+
+	if err := e.worker.PerformContext(ctx, job); err != nil {
+		e.logger.Error(
+			"unable to perform workflow failure job",
+			slog.String("workflow", id),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// performStage performs the jobs of the current stage of state.
+func (e *Engine) performStage(ctx context.Context, state State) error {
+	for i, job := range state.Stages[state.Stage] {
+		job.Args = maps.Clone(job.Args)
+		if job.Args == nil {
+			job.Args = worker.Args{}
+		}
+
+		job.Args[StepArg] = step{id: state.ID, stage: state.Stage, index: i}.String()
+
+		if err := e.worker.PerformContext(ctx, job); err != nil {
+			return fmt.Errorf("perform workflow job %s: %w", job.Handler, err)
+		}
+	}
+
+	return nil
+}
+
+// step identifies a job of a workflow.
+type step struct {
+	id    string
+	stage int
+	index int
+}
+
+// String encodes s as <id>/<stage>/<index>.
+func (s step) String() string {
+	return fmt.Sprintf("%s/%d/%d", s.id, s.stage, s.index)
+}
+
+// parseStep returns the step in args, set by performStage.
+func parseStep(args worker.Args) (step, bool) {
+	v, ok := args[StepArg].(string)
+	if !ok {
+		return step{}, false
+	}
+
+	parts := strings.Split(v, "/")
+	if len(parts) != 3 {
+		return step{}, false
+	}
+
+	stage, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return step{}, false
+	}
+
+	index, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return step{}, false
+	}
+
+	return step{id: parts[0], stage: stage, index: index}, true
+}
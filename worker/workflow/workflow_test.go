@@ -0,0 +1,296 @@
+package workflow_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/purposeinplay/go-commons/worker"
+	"github.com/purposeinplay/go-commons/worker/memw"
+	"github.com/purposeinplay/go-commons/worker/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the handlers that ran.
+type recorder struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+func (r *recorder) handler(name string, err error) worker.ContextHandler {
+	return func(context.Context, worker.Args) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.runs = append(r.runs, name)
+
+		return err
+	}
+}
+
+func (r *recorder) ran() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.runs...)
+}
+
+func TestEngine_Chain(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := memw.New(memw.Options{})
+	e := workflow.New(w, workflow.Options{})
+
+	var rec recorder
+
+	for _, name := range []string{"fetch", "transform", "notify"} {
+		r.NoError(e.Register(name, rec.handler(name, nil)))
+	}
+
+	id, err := e.Start(ctx, workflow.Chain(
+		workflow.Job(worker.Job{Handler: "fetch"}),
+		workflow.Job(worker.Job{Handler: "transform"}),
+		workflow.Job(worker.Job{Handler: "notify"}),
+	))
+	r.NoError(err)
+
+	// Only the first job is enqueued.
+	r.Len(w.Enqueued("fetch"), 1)
+	r.Empty(w.Enqueued("transform"))
+
+	r.NoError(w.RunUntilIdle(ctx))
+	r.Equal([]string{"fetch", "transform", "notify"}, rec.ran())
+
+	state, err := e.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(workflow.StatusSucceeded, state.Status)
+}
+
+func TestEngine_Chord(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := memw.New(memw.Options{MaxConcurrency: 3})
+	e := workflow.New(w, workflow.Options{})
+
+	var rec recorder
+
+	r.NoError(e.Register("item", rec.handler("item", nil)))
+	r.NoError(e.Register("aggregate", rec.handler("aggregate", nil)))
+
+	id, err := e.Start(ctx, workflow.Chord(
+		worker.Job{Handler: "aggregate"},
+		worker.Job{Handler: "item", Args: worker.Args{"n": 1}},
+		worker.Job{Handler: "item", Args: worker.Args{"n": 2}},
+		worker.Job{Handler: "item", Args: worker.Args{"n": 3}},
+	))
+	r.NoError(err)
+
+	r.NoError(w.RunUntilIdle(ctx))
+	r.Equal([]string{"item", "item", "item", "aggregate"}, rec.ran())
+
+	state, err := e.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(workflow.StatusSucceeded, state.Status)
+}
+
+func TestEngine_Failure(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := memw.New(memw.Options{RetryPolicy: worker.NoRetry()})
+	e := workflow.New(w, workflow.Options{})
+
+	var rec recorder
+
+	r.NoError(e.Register("fetch", rec.handler("fetch", nil)))
+	r.NoError(e.Register("transform", rec.handler("transform", errors.New("bad input"))))
+	r.NoError(e.Register("notify", rec.handler("notify", nil)))
+
+	var failure worker.Args
+
+	r.NoError(w.RegisterContext("cleanup", func(_ context.Context, args worker.Args) error {
+		failure = args
+
+		return nil
+	}))
+
+	id, err := e.Start(ctx, workflow.Chain(
+		workflow.Job(worker.Job{Handler: "fetch"}),
+		workflow.Group(
+			worker.Job{Handler: "transform"},
+			worker.Job{Handler: "fetch"},
+		),
+		workflow.Job(worker.Job{Handler: "notify"}),
+	), workflow.WithOnFailure(worker.Job{Handler: "cleanup", Args: worker.Args{"reason": "cleanup"}}))
+	r.NoError(err)
+
+	r.NoError(w.RunUntilIdle(ctx))
+
+	// The stage after the dead job doesn't run.
+	r.NotContains(rec.ran(), "notify")
+
+	state, err := e.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(workflow.StatusFailed, state.Status)
+	r.Equal("bad input", state.Error)
+
+	r.Equal(worker.Args{
+		"reason":          "cleanup",
+		workflow.IDArg:    id,
+		workflow.ErrorArg: "bad input",
+	}, failure)
+}
+
+func TestEngine_Retry(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := memw.New(memw.Options{RetryPolicy: worker.RetryPolicy{MaxAttempts: 2}})
+	e := workflow.New(w, workflow.Options{})
+
+	var rec recorder
+
+	fail := true
+
+	r.NoError(e.Register("flaky", func(ctx context.Context, args worker.Args) error {
+		if fail {
+			fail = false
+
+			return errors.New("failed")
+		}
+
+		return rec.handler("flaky", nil)(ctx, args)
+	}))
+	r.NoError(e.Register("notify", rec.handler("notify", nil)))
+
+	id, err := e.Start(ctx, workflow.Chain(
+		workflow.Job(worker.Job{Handler: "flaky"}),
+		workflow.Job(worker.Job{Handler: "notify"}),
+	))
+	r.NoError(err)
+
+	// A failed attempt that is retried doesn't fail the workflow.
+	r.NoError(w.Drain(ctx))
+	r.Equal([]string{"flaky", "notify"}, rec.ran())
+
+	state, err := e.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(workflow.StatusSucceeded, state.Status)
+}
+
+// crashingWorker panics the first time a job of crash is performed, like a
+// worker stopping between the advance of a workflow and the next stage.
+type crashingWorker struct {
+	*memw.Worker
+	crash   string
+	crashed bool
+}
+
+func (w *crashingWorker) PerformContext(ctx context.Context, job worker.Job) error {
+	if job.Handler == w.crash && !w.crashed {
+		w.crashed = true
+
+		panic("worker stopped")
+	}
+
+	return w.Worker.PerformContext(ctx, job)
+}
+
+func TestEngine_Resume(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := &crashingWorker{
+		Worker: memw.New(memw.Options{RetryPolicy: worker.RetryPolicy{MaxAttempts: 2}}),
+		crash:  "notify",
+	}
+	e := workflow.New(w, workflow.Options{})
+
+	var rec recorder
+
+	r.NoError(e.Register("fetch", rec.handler("fetch", nil)))
+	r.NoError(e.Register("notify", rec.handler("notify", nil)))
+
+	id, err := e.Start(ctx, workflow.Chain(
+		workflow.Job(worker.Job{Handler: "fetch"}),
+		workflow.Job(worker.Job{Handler: "notify"}),
+	))
+	r.NoError(err)
+
+	// The second delivery of fetch performs the stage that was reached but
+	// not performed.
+	r.NoError(w.Drain(ctx))
+	r.True(w.crashed)
+	r.Equal([]string{"fetch", "fetch", "notify"}, rec.ran())
+
+	state, err := e.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(workflow.StatusSucceeded, state.Status)
+}
+
+func TestEngine_Permanent(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	w := memw.New(memw.Options{RetryPolicy: worker.DefaultRetryPolicy()})
+	e := workflow.New(w, workflow.Options{})
+
+	var rec recorder
+
+	r.NoError(e.Register("fetch", rec.handler("fetch", worker.Permanent(errors.New("bad input")))))
+	r.NoError(e.Register("notify", rec.handler("notify", nil)))
+
+	id, err := e.Start(ctx, workflow.Chain(
+		workflow.Job(worker.Job{Handler: "fetch"}),
+		workflow.Job(worker.Job{Handler: "notify"}),
+	))
+	r.NoError(err)
+
+	// A permanent error fails the workflow on the first attempt.
+	r.NoError(w.RunUntilIdle(ctx))
+	r.Equal([]string{"fetch"}, rec.ran())
+
+	state, err := e.Get(ctx, id)
+	r.NoError(err)
+	r.Equal(workflow.StatusFailed, state.Status)
+}
+
+func TestEngine_Start(t *testing.T) {
+	r := require.New(t)
+
+	e := workflow.New(memw.New(memw.Options{}), workflow.Options{})
+
+	_, err := e.Start(context.Background(), workflow.Chain())
+	r.ErrorIs(err, workflow.ErrEmpty)
+
+	_, err = e.Get(context.Background(), "unknown")
+	r.ErrorIs(err, workflow.ErrNotFound)
+}
+
+func TestMemoryStore_Update(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	store := workflow.NewMemoryStore()
+
+	r.NoError(store.Create(ctx, workflow.State{ID: "1", Status: workflow.StatusRunning}))
+
+	// The state is saved only when fn returns true.
+	state, err := store.Update(ctx, "1", func(s *workflow.State) bool {
+		s.Status = workflow.StatusFailed
+
+		return false
+	})
+	r.NoError(err)
+	r.Equal(workflow.StatusFailed, state.Status)
+
+	state, err = store.Get(ctx, "1")
+	r.NoError(err)
+	r.Equal(workflow.StatusRunning, state.Status)
+
+	_, err = store.Update(ctx, "2", func(*workflow.State) bool { return true })
+	r.ErrorIs(err, workflow.ErrNotFound)
+}